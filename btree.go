@@ -37,6 +37,12 @@ func NewBTree() *BTree {
 	}
 }
 
+// Copy returns a copy of the tree. The copy is lazy, the nodes are shared and copied
+// on write, so it is cheap and the changes of either tree are not visible to the other.
+func (bt *BTree) Copy() *BTree {
+	return &BTree{btree: bt.btree.Copy()}
+}
+
 func (bt *BTree) Find(key []byte) (*Record, bool) {
 	item, ok := bt.btree.Get(&Item{key: key})
 	if ok {
@@ -0,0 +1,210 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+const (
+	// CompressionNone marks the entries whose value is stored as is.
+	CompressionNone uint8 = 0
+
+	// CompressionFlate marks the entries whose value is compressed by FlateCompressor.
+	CompressionFlate uint8 = 1
+)
+
+var (
+	// ErrUnknownCompressor is returned when an entry is compressed by a compressor that is not registered.
+	ErrUnknownCompressor = errors.New("the compressor of the entry is not registered")
+
+	// ErrCompressorID is returned when registering a compressor whose ID is 0 or is used by another compressor.
+	ErrCompressorID = errors.New("the compressor id is 0 or used by another compressor")
+)
+
+// Compressor compresses the values of the entries written to the data files.
+//
+// The ID of the compressor is recorded in every entry it compressed, so the entries
+// compressed by different compressors, or not compressed, can be read back as long as
+// their compressors are registered.
+type Compressor interface {
+	// ID returns the id of the compressor, it must not be 0 and must never change.
+	ID() uint8
+
+	// Compress returns the compressed src.
+	Compress(src []byte) ([]byte, error)
+
+	// Decompress returns the decompressed src.
+	Decompress(src []byte) ([]byte, error)
+}
+
+// FlateCompressor is the built-in compressor using the DEFLATE format of compress/flate.
+type FlateCompressor struct {
+	// Level is the compression level, see compress/flate.
+	Level int
+}
+
+// DefaultCompressor is the built-in compressor favouring speed over compression ratio.
+var DefaultCompressor Compressor = FlateCompressor{Level: flate.BestSpeed}
+
+// ID returns CompressionFlate.
+func (c FlateCompressor) ID() uint8 {
+	return CompressionFlate
+}
+
+// Compress returns the DEFLATE compressed src.
+func (c FlateCompressor) Compress(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := flate.NewWriter(&buf, c.Level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the DEFLATE decompressed src.
+func (c FlateCompressor) Decompress(src []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(src))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+var compressors = struct {
+	sync.RWMutex
+	m map[uint8]Compressor
+}{
+	m: map[uint8]Compressor{CompressionFlate: DefaultCompressor},
+}
+
+// RegisterCompressor registers a compressor to read the entries it compressed.
+// The compressors set in Options are registered when the db is opened.
+func RegisterCompressor(c Compressor) error {
+	if c == nil {
+		return nil
+	}
+
+	compressors.Lock()
+	defer compressors.Unlock()
+
+	id := c.ID()
+	if id == CompressionNone {
+		return ErrCompressorID
+	}
+	if registered, ok := compressors.m[id]; ok && reflect.TypeOf(registered) != reflect.TypeOf(c) {
+		return ErrCompressorID
+	}
+
+	compressors.m[id] = c
+
+	return nil
+}
+
+// getCompressor returns the registered compressor with the given id.
+func getCompressor(id uint8) (Compressor, error) {
+	compressors.RLock()
+	defer compressors.RUnlock()
+
+	c, ok := compressors.m[id]
+	if !ok {
+		return nil, ErrUnknownCompressor
+	}
+
+	return c, nil
+}
+
+// registerCompressors registers the compressors set in the options.
+func (db *DB) registerCompressors() error {
+	if err := RegisterCompressor(db.opt.Compressor); err != nil {
+		return err
+	}
+
+	for _, c := range db.opt.BucketCompressors {
+		if err := RegisterCompressor(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getCompressor returns the compressor of the values written to the bucket, nil if
+// the values are not compressed.
+func (db *DB) getCompressor(bucket string) Compressor {
+	if c, ok := db.opt.BucketCompressors[bucket]; ok {
+		return c
+	}
+
+	return db.opt.Compressor
+}
+
+// compress compresses the value of the entry with c, the value is kept as is if c is
+// nil or the value does not get smaller.
+func (e *Entry) compress(c Compressor) error {
+	if c == nil || len(e.Value) == 0 {
+		return nil
+	}
+
+	compressed, err := c.Compress(e.Value)
+	if err != nil {
+		return err
+	}
+
+	if len(compressed) >= len(e.Value) {
+		return nil
+	}
+
+	e.storedValue = compressed
+	e.Meta.ValueSize = uint32(len(compressed))
+	e.Meta.Compression = c.ID()
+
+	return nil
+}
+
+// decompress decompresses the value stored in the data file.
+func (e *Entry) decompress(stored []byte) error {
+	e.storedValue = stored
+
+	c, err := getCompressor(e.Meta.Compression)
+	if err != nil {
+		return err
+	}
+
+	e.Value, err = c.Decompress(stored)
+
+	return err
+}
+
+// getStoredValue returns the value as it is stored in the data file.
+func (e *Entry) getStoredValue() []byte {
+	if e.Meta.Compression != CompressionNone {
+		return e.storedValue
+	}
+
+	return e.Value
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCompressor struct {
+	id uint8
+}
+
+func (c testCompressor) ID() uint8 {
+	return c.id
+}
+
+func (c testCompressor) Compress(src []byte) ([]byte, error) {
+	return src[:len(src)/2], nil
+}
+
+func (c testCompressor) Decompress(src []byte) ([]byte, error) {
+	return append(append([]byte{}, src...), src...), nil
+}
+
+func TestFlateCompressor(t *testing.T) {
+	src := bytes.Repeat([]byte("nutsdb"), 100)
+
+	compressed, err := DefaultCompressor.Compress(src)
+	require.NoError(t, err)
+	require.Less(t, len(compressed), len(src))
+
+	decompressed, err := DefaultCompressor.Decompress(compressed)
+	require.NoError(t, err)
+	require.Equal(t, src, decompressed)
+}
+
+func TestRegisterCompressor(t *testing.T) {
+	require.Equal(t, ErrCompressorID, RegisterCompressor(testCompressor{id: CompressionNone}))
+	require.Equal(t, ErrCompressorID, RegisterCompressor(testCompressor{id: CompressionFlate}))
+	require.NoError(t, RegisterCompressor(FlateCompressor{Level: 9}))
+	require.NoError(t, RegisterCompressor(DefaultCompressor))
+
+	_, err := getCompressor(201)
+	require.Equal(t, ErrUnknownCompressor, err)
+	require.NoError(t, RegisterCompressor(testCompressor{id: 201}))
+	c, err := getCompressor(201)
+	require.NoError(t, err)
+	require.Equal(t, testCompressor{id: 201}, c)
+}
+
+func TestDB_Compression(t *testing.T) {
+	bucket := "bucket"
+	value := func(i int) []byte {
+		return bytes.Repeat(GetTestBytes(i), 20)
+	}
+
+	for _, mode := range []EntryIdxMode{HintKeyValAndRAMIdxMode, HintKeyAndRAMIdxMode, HintBPTSparseIdxMode} {
+		opts := DefaultOptions
+		opts.EntryIdxMode = mode
+		opts.SegmentSize = 8 * KB
+		opts.Compressor = DefaultCompressor
+
+		runNutsDBTest(t, &opts, func(t *testing.T, db *DB) {
+			for i := 0; i < 100; i++ {
+				txPut(t, db, bucket, GetTestBytes(i), value(i), Persistent, nil)
+			}
+			txPut(t, db, bucket, []byte("empty"), []byte{}, Persistent, nil)
+
+			if mode == HintKeyValAndRAMIdxMode {
+				require.NoError(t, db.View(func(tx *Tx) error {
+					e, err := tx.Get(bucket, GetTestBytes(0))
+					require.NoError(t, err)
+					require.Equal(t, CompressionFlate, e.Meta.Compression)
+					require.Less(t, int(e.Meta.ValueSize), len(value(0)))
+					return nil
+				}))
+			}
+
+			require.NoError(t, db.Close())
+			db, err := Open(db.opt)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, db.Close())
+			}()
+
+			for i := 0; i < 100; i++ {
+				txGet(t, db, bucket, GetTestBytes(i), value(i), nil)
+			}
+			txGet(t, db, bucket, []byte("empty"), []byte{}, nil)
+		})
+	}
+}
+
+func TestDB_CompressionMixedEntries(t *testing.T) {
+	bucket := "bucket"
+	plain := bytes.Repeat([]byte("plain"), 20)
+	compressed := bytes.Repeat([]byte("compressed"), 20)
+
+	opts := DefaultOptions
+	opts.SegmentSize = 8 * KB
+	runNutsDBTest(t, &opts, func(t *testing.T, db *DB) {
+		for i := 0; i < 20; i++ {
+			txPut(t, db, bucket, GetTestBytes(i), plain, Persistent, nil)
+		}
+		require.NoError(t, db.Close())
+
+		// the entries written before the compressor is set are still readable.
+		db, err := Open(db.opt, WithCompressor(DefaultCompressor))
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			txGet(t, db, bucket, GetTestBytes(i), plain, nil)
+		}
+		for i := 10; i < 30; i++ {
+			txPut(t, db, bucket, GetTestBytes(i), compressed, Persistent, nil)
+		}
+		require.NoError(t, db.Close())
+
+		// and the compressed entries are still readable once it is unset.
+		db, err = Open(db.opt, WithCompressor(nil))
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, db.Close())
+		}()
+		for i := 0; i < 10; i++ {
+			txGet(t, db, bucket, GetTestBytes(i), plain, nil)
+		}
+		for i := 10; i < 30; i++ {
+			txGet(t, db, bucket, GetTestBytes(i), compressed, nil)
+		}
+	})
+}
+
+func TestDB_BucketCompressor(t *testing.T) {
+	value := bytes.Repeat([]byte("nutsdb"), 20)
+
+	opts := DefaultOptions
+	opts.Compressor = DefaultCompressor
+	WithBucketCompressor("plain", nil)(&opts)
+	runNutsDBTest(t, &opts, func(t *testing.T, db *DB) {
+		txPut(t, db, "plain", []byte("key"), value, Persistent, nil)
+		txPut(t, db, "compressed", []byte("key"), value, Persistent, nil)
+
+		require.NoError(t, db.View(func(tx *Tx) error {
+			e, err := tx.Get("plain", []byte("key"))
+			require.NoError(t, err)
+			require.Equal(t, CompressionNone, e.Meta.Compression)
+
+			e, err = tx.Get("compressed", []byte("key"))
+			require.NoError(t, err)
+			require.Equal(t, CompressionFlate, e.Meta.Compression)
+			return nil
+		}))
+	})
+}
+
+func TestDB_MergeCompressedEntries(t *testing.T) {
+	bucket := "bucket"
+	value := func(i int) []byte {
+		return bytes.Repeat(GetTestBytes(i), 20)
+	}
+
+	for _, mode := range []EntryIdxMode{HintKeyValAndRAMIdxMode, HintKeyAndRAMIdxMode} {
+		opts := DefaultOptions
+		opts.EntryIdxMode = mode
+		opts.SegmentSize = 8 * KB
+		opts.Compressor = DefaultCompressor
+
+		runNutsDBTest(t, &opts, func(t *testing.T, db *DB) {
+			for i := 0; i < 200; i++ {
+				txPut(t, db, bucket, GetTestBytes(i), GetRandomBytes(24), Persistent, nil)
+				txPut(t, db, bucket, GetTestBytes(i), value(i), Persistent, nil)
+			}
+
+			require.NoError(t, db.Merge())
+
+			for i := 0; i < 200; i++ {
+				txGet(t, db, bucket, GetTestBytes(i), value(i), nil)
+			}
+
+			require.NoError(t, db.Close())
+			db, err := Open(db.opt)
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, db.Close())
+			}()
+
+			for i := 0; i < 200; i++ {
+				txGet(t, db, bucket, GetTestBytes(i), value(i), nil)
+			}
+		})
+	}
+}
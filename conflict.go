@@ -0,0 +1,243 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrConflict is returned by Commit when the data read or written by the tx was modified
+// by another tx committed after it began, the tx can be retried.
+var ErrConflict = errors.New("tx conflicts with a concurrent tx, please retry")
+
+// conflictKey identifies the data a tx depends on.
+//
+// The key of a list, set or sorted set is the key of the whole structure, not of an item.
+// An empty key stands for the whole bucket, an empty bucket stands for all the buckets of
+// the data structure.
+type conflictKey struct {
+	ds     uint16
+	bucket string
+	key    string
+}
+
+// committedTx is the write set of a committed tx.
+type committedTx struct {
+	commitTs uint64
+	keys     map[conflictKey]struct{} // the keys written, and the whole buckets deleted
+	buckets  map[conflictKey]struct{} // the buckets the tx wrote to
+}
+
+// conflictDetector keeps the write sets of the committed transactions needed to
+// validate the running write transactions.
+//
+// A write tx reads from a snapshot of the indexes that contains the changes of all the
+// transactions committed at or before its read ts. When it commits, it conflicts with
+// the transactions committed after its read ts that wrote any data it read or wrote.
+type conflictDetector struct {
+	mu        sync.Mutex
+	commitTs  uint64         // the commit ts of the latest committed write tx
+	running   map[uint64]int // the number of running write transactions by read ts
+	committed []*committedTx // the write sets committed after the oldest running write tx began
+}
+
+func newConflictDetector() *conflictDetector {
+	return &conflictDetector{running: make(map[uint64]int)}
+}
+
+// begin returns the read ts of a tx, a running write tx is tracked until done is called.
+// It is called with db.idxLock held, so the read ts matches the snapshot of the tx.
+func (cd *conflictDetector) begin(writable bool) uint64 {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	if writable {
+		cd.running[cd.commitTs]++
+	}
+
+	return cd.commitTs
+}
+
+// done stops tracking a running write tx.
+func (cd *conflictDetector) done(readTs uint64) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	if cd.running[readTs]--; cd.running[readTs] <= 0 {
+		delete(cd.running, readTs)
+		cd.prune()
+	}
+}
+
+// hasConflict reports whether the keys a tx with the given read ts depends on were
+// written by a tx committed after it.
+func (cd *conflictDetector) hasConflict(readTs uint64, keys map[conflictKey]struct{}) bool {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	for _, c := range cd.committed {
+		if c.commitTs <= readTs {
+			continue
+		}
+		for k := range keys {
+			if c.conflictsWith(k) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// commit records the write set of a committed tx, it is called with db.idxLock held.
+func (cd *conflictDetector) commit(keys []conflictKey) {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	cd.commitTs++
+
+	if len(cd.running) > 0 && len(keys) > 0 {
+		c := &committedTx{
+			commitTs: cd.commitTs,
+			keys:     make(map[conflictKey]struct{}, len(keys)),
+			buckets:  make(map[conflictKey]struct{}),
+		}
+		for _, k := range keys {
+			c.keys[k] = struct{}{}
+			c.buckets[conflictKey{ds: k.ds, bucket: k.bucket}] = struct{}{}
+		}
+		cd.committed = append(cd.committed, c)
+	}
+
+	cd.prune()
+}
+
+// prune drops the write sets no running write tx can conflict with.
+func (cd *conflictDetector) prune() {
+	if len(cd.running) == 0 {
+		cd.committed = nil
+		return
+	}
+
+	oldest := cd.commitTs
+	for readTs := range cd.running {
+		if readTs < oldest {
+			oldest = readTs
+		}
+	}
+
+	i := 0
+	for i < len(cd.committed) && cd.committed[i].commitTs <= oldest {
+		i++
+	}
+	cd.committed = cd.committed[i:]
+}
+
+// conflictsWith reports whether the committed tx wrote the data k stands for.
+func (c *committedTx) conflictsWith(k conflictKey) bool {
+	switch {
+	case k.bucket == "":
+		for b := range c.buckets {
+			if b.ds == k.ds {
+				return true
+			}
+		}
+		return false
+	case k.key == "":
+		_, ok := c.buckets[k]
+		return ok
+	default:
+		if _, ok := c.keys[k]; ok {
+			return true
+		}
+		_, ok := c.keys[conflictKey{ds: k.ds, bucket: k.bucket}]
+		return ok
+	}
+}
+
+// isOptimistic reports whether the tx is a write tx that runs concurrently with the
+// other write transactions and is validated when it commits.
+func (tx *Tx) isOptimistic() bool {
+	return tx.writable && tx.snapshot != nil
+}
+
+// trackRead records the data read by an optimistic tx, a nil key stands for the whole bucket.
+func (tx *Tx) trackRead(ds uint16, bucket string, key []byte) {
+	if !tx.isOptimistic() {
+		return
+	}
+
+	if tx.reads == nil {
+		tx.reads = make(map[conflictKey]struct{})
+	}
+	tx.reads[conflictKey{ds: ds, bucket: bucket, key: string(key)}] = struct{}{}
+}
+
+// conflictWrites returns the keys written by the pending writes of the tx.
+func (tx *Tx) conflictWrites() []conflictKey {
+	keys := make([]conflictKey, 0, len(tx.pendingWrites))
+
+	for _, entry := range tx.pendingWrites {
+		bucket, key := string(entry.Bucket), string(entry.Key)
+
+		switch entry.Meta.Ds {
+		case DataStructureList:
+			// the key of LSet and LTrim is suffixed by the index.
+			key, _, _ = strings.Cut(key, SeparatorForListKey)
+		case DataStructureSortedSet:
+			// the key of ZAdd is suffixed by the score.
+			key, _, _ = strings.Cut(key, SeparatorForZSetKey)
+		case DataStructureNone:
+			// the whole bucket is deleted.
+			keys = append(keys, conflictKey{ds: bucketDeleteFlagToDs(entry.Meta.Flag), bucket: bucket})
+			continue
+		}
+
+		keys = append(keys, conflictKey{ds: entry.Meta.Ds, bucket: bucket, key: key})
+	}
+
+	return keys
+}
+
+// hasConflict reports whether the optimistic tx conflicts with a tx committed after it began.
+func (tx *Tx) hasConflict() bool {
+	keys := make(map[conflictKey]struct{}, len(tx.reads)+len(tx.pendingWrites))
+	for k := range tx.reads {
+		keys[k] = struct{}{}
+	}
+	for _, k := range tx.conflictWrites() {
+		keys[k] = struct{}{}
+	}
+
+	return tx.db.conflicts.hasConflict(tx.snapshot.readTs, keys)
+}
+
+// bucketDeleteFlagToDs returns the data structure of the bucket deleted by the entry flag.
+func bucketDeleteFlagToDs(flag uint16) uint16 {
+	switch flag {
+	case DataSetBucketDeleteFlag:
+		return DataStructureSet
+	case DataSortedSetBucketDeleteFlag:
+		return DataStructureSortedSet
+	case DataBPTreeBucketDeleteFlag:
+		return DataStructureTree
+	case DataListBucketDeleteFlag:
+		return DataStructureList
+	}
+
+	return DataStructureNone
+}
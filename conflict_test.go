@@ -0,0 +1,176 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xujiajun/utils/strconv2"
+)
+
+func TestTx_Conflict(t *testing.T) {
+	bucket := "bucket"
+	key := []byte("key")
+
+	begin := func(t *testing.T, db *DB) (*Tx, *Tx) {
+		tx1, err := db.Begin(true)
+		require.NoError(t, err)
+		tx2, err := db.Begin(true)
+		require.NoError(t, err)
+		return tx1, tx2
+	}
+
+	t.Run("write write conflict", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			tx1, tx2 := begin(t, db)
+			require.NoError(t, tx1.Put(bucket, key, []byte("v1"), Persistent))
+			require.NoError(t, tx2.Put(bucket, key, []byte("v2"), Persistent))
+			require.NoError(t, tx1.Commit())
+			require.Equal(t, ErrConflict, tx2.Commit())
+
+			txGet(t, db, bucket, key, []byte("v1"), nil)
+		})
+	})
+
+	t.Run("read write conflict", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			txPut(t, db, bucket, key, []byte("v0"), Persistent, nil)
+
+			tx1, tx2 := begin(t, db)
+			_, err := tx1.Get(bucket, key)
+			require.NoError(t, err)
+			require.NoError(t, tx1.Put(bucket, []byte("other"), []byte("v1"), Persistent))
+			require.NoError(t, tx2.Put(bucket, key, []byte("v2"), Persistent))
+			require.NoError(t, tx2.Commit())
+			require.Equal(t, ErrConflict, tx1.Commit())
+
+			txGet(t, db, bucket, []byte("other"), nil, ErrKeyNotFound)
+		})
+	})
+
+	t.Run("no conflict on different keys", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			tx1, tx2 := begin(t, db)
+			require.NoError(t, tx1.Put(bucket, []byte("key1"), []byte("v1"), Persistent))
+			require.NoError(t, tx2.Put(bucket, []byte("key2"), []byte("v2"), Persistent))
+			require.NoError(t, tx2.Commit())
+			require.NoError(t, tx1.Commit())
+
+			txGet(t, db, bucket, []byte("key1"), []byte("v1"), nil)
+			txGet(t, db, bucket, []byte("key2"), []byte("v2"), nil)
+		})
+	})
+
+	t.Run("scan conflicts with a write in the bucket", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			txPut(t, db, bucket, []byte("key0"), []byte("v0"), Persistent, nil)
+
+			tx1, tx2 := begin(t, db)
+			_, err := tx1.PrefixScan(bucket, []byte("key"), 0, 10)
+			require.NoError(t, err)
+			require.NoError(t, tx1.Put("other", key, []byte("v1"), Persistent))
+			require.NoError(t, tx2.Put(bucket, []byte("key1"), []byte("v2"), Persistent))
+			require.NoError(t, tx2.Commit())
+			require.Equal(t, ErrConflict, tx1.Commit())
+		})
+	})
+
+	t.Run("delete bucket conflicts with a read in the bucket", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			txSAdd(t, db, bucket, key, []byte("m0"), nil)
+
+			tx1, tx2 := begin(t, db)
+			_, err := tx1.SMembers(bucket, key)
+			require.NoError(t, err)
+			require.NoError(t, tx1.Put(bucket, key, []byte("v1"), Persistent))
+			require.NoError(t, tx2.DeleteBucket(DataStructureSet, bucket))
+			require.NoError(t, tx2.Commit())
+			require.Equal(t, ErrConflict, tx1.Commit())
+		})
+	})
+
+	t.Run("list conflict", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			txPush(t, db, bucket, key, []byte("i0"), nil, true)
+			txPush(t, db, bucket, key, []byte("i1"), nil, true)
+
+			tx1, tx2 := begin(t, db)
+			_, err := tx1.LPop(bucket, key)
+			require.NoError(t, err)
+			_, err = tx2.LPop(bucket, key)
+			require.NoError(t, err)
+			require.NoError(t, tx1.Commit())
+			require.Equal(t, ErrConflict, tx2.Commit())
+
+			txRange(t, db, bucket, key, 0, -1, 1)
+		})
+	})
+
+	t.Run("rolled back tx does not conflict", func(t *testing.T) {
+		withDefaultDB(t, func(t *testing.T, db *DB) {
+			tx1, tx2 := begin(t, db)
+			require.NoError(t, tx1.Put(bucket, key, []byte("v1"), Persistent))
+			require.NoError(t, tx1.Rollback())
+			require.NoError(t, tx2.Put(bucket, key, []byte("v2"), Persistent))
+			require.NoError(t, tx2.Commit())
+
+			txGet(t, db, bucket, key, []byte("v2"), nil)
+		})
+	})
+}
+
+func TestDB_ConcurrentUpdate(t *testing.T) {
+	bucket := "bucket"
+	key := []byte("counter")
+
+	withDefaultDB(t, func(t *testing.T, db *DB) {
+		txPut(t, db, bucket, key, []byte("0"), Persistent, nil)
+
+		incr := func() error {
+			return db.Update(func(tx *Tx) error {
+				e, err := tx.Get(bucket, key)
+				if err != nil {
+					return err
+				}
+				n, err := strconv2.StrToInt(string(e.Value))
+				if err != nil {
+					return err
+				}
+				return tx.Put(bucket, key, []byte(strconv2.IntToStr(n+1)), Persistent)
+			})
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					err := incr()
+					for err == ErrConflict {
+						err = incr()
+					}
+					require.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		txGet(t, db, bucket, key, []byte("400"), nil)
+		require.Empty(t, db.conflicts.committed)
+	})
+}
@@ -30,6 +30,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bwmarrin/snowflake"
 	"github.com/gofrs/flock"
 	"github.com/xujiajun/utils/filesystem"
 	"github.com/xujiajun/utils/strconv2"
@@ -150,13 +151,18 @@ type (
 		ActiveBPTreeIdx         *BPTree          // 简单kv数据使用，b+树稀疏索引（HintBPTSparseIdxMode）用这个字段，b+树稀疏索引
 		ActiveCommittedTxIdsIdx *BPTree          // 保存已提交的事务id，b+树稀疏索引使用
 		MaxFileID               int64
-		mu                      sync.RWMutex // 读写锁，对db进行读写加锁
-		KeyCount                int          // total key number ,include expired, deleted, repeated.
+		mu                      sync.RWMutex      // 读写锁，对db进行读写加锁
+		idxLock                 sync.RWMutex      // guards the in-memory indexes from being modified while a snapshot is taken
+		snapshots               *snapshots        // the live snapshots of the transactions
+		conflicts               *conflictDetector // detects the conflicts between the concurrent write transactions
+		KeyCount                int               // total key number ,include expired, deleted, repeated.
 		closed                  bool
 		isMerging               bool
 		fm                      *fileManager
 		flock                   *flock.Flock
 		commitBuffer            *bytes.Buffer
+		hintBuffer              *bytes.Buffer   // hint records of the active file, flushed to the hint file when the file is rotated
+		snowflakeNode           *snowflake.Node // generates the tx ids, ids from a single node are unique and increasing
 		mergeStartCh            chan struct{}
 		mergeEndCh              chan error
 		mergeWorkCloseCh        chan struct{}
@@ -190,11 +196,21 @@ func open(opt Options) (*DB, error) {
 		mergeWorkCloseCh:        make(chan struct{}),
 		writeCh:                 make(chan *request, KvWriteChCapacity),
 		tm:                      newTTLManager(opt.ExpiredDeleteType),
+		snapshots:               newSnapshots(),
+		conflicts:               newConflictDetector(),
 	}
 
 	commitBuffer := new(bytes.Buffer)
 	commitBuffer.Grow(int(db.opt.CommitBufferSize))
 	db.commitBuffer = commitBuffer
+	db.hintBuffer = new(bytes.Buffer)
+
+	// an invalid NodeNum is reported when a tx begins.
+	db.snowflakeNode, _ = snowflake.NewNode(opt.NodeNum)
+
+	if err := db.registerCompressors(); err != nil {
+		return nil, err
+	}
 
 	if ok := filesystem.PathIsExist(db.opt.Dir); !ok {
 		if err := os.MkdirAll(db.opt.Dir, os.ModePerm); err != nil {
@@ -308,28 +324,52 @@ func (db *DB) View(fn func(tx *Tx) error) error {
 
 // Backup copies the database to file directory at the given dir.
 func (db *DB) Backup(dir string) error {
-	return db.View(func(tx *Tx) error {
+	return db.withWritesBlocked(func() error {
 		return filesystem.CopyDir(db.opt.Dir, dir)
 	})
 }
 
 // BackupTarGZ Backup copy the database to writer.
 func (db *DB) BackupTarGZ(w io.Writer) error {
-	return db.View(func(tx *Tx) error {
+	return db.withWritesBlocked(func() error {
 		return tarGZCompress(w, db.opt.Dir)
 	})
 }
 
+// withWritesBlocked calls fn while no write transaction can run. The read-only
+// transactions do not block the writers, so the files are copied under the db lock.
+func (db *DB) withWritesBlocked(fn func() error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return ErrDBClosed
+	}
+
+	return fn()
+}
+
 // Close releases all db resources.
 func (db *DB) Close() error {
 	db.mu.Lock()
-	defer db.mu.Unlock()
 
 	if db.closed {
+		db.mu.Unlock()
 		return ErrDBClosed
 	}
 
+	db.idxLock.Lock()
 	db.closed = true
+	db.idxLock.Unlock()
+
+	db.mu.Unlock()
+
+	// the running transactions still read from the indexes and the data files,
+	// the write transactions fail to commit once the db is closed.
+	db.waitForAllSnapshots()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
 	err := db.release()
 	if err != nil {
@@ -657,7 +697,34 @@ func (db *DB) parseDataFiles(dataFileIds []int) (err error) {
 		return nil
 	}
 
-	var readEntriesFromFile = func() error {
+	parseEntry := func(entry *Entry) error {
+		if dataInTx.txId == 0 {
+			dataInTx.appendEntry(entry)
+			dataInTx.txId = entry.Meta.TxID
+			dataInTx.startOff = off
+		} else if dataInTx.isSameTx(entry) {
+			dataInTx.appendEntry(entry)
+		}
+
+		if entry.Meta.Status == Committed {
+			err := parseDataInTx()
+			if err != nil {
+				return err
+			}
+			dataInTx.reset()
+			dataInTx.startOff = off
+		}
+
+		if !dataInTx.isSameTx(entry) {
+			dataInTx.reset()
+			dataInTx.startOff = off
+		}
+
+		off += entry.Size()
+		return nil
+	}
+
+	var readEntriesFromFile = func(hintBuf *bytes.Buffer) error {
 		for {
 			entry, err := f.readEntry()
 			if err != nil {
@@ -677,29 +744,13 @@ func (db *DB) parseDataFiles(dataFileIds []int) (err error) {
 				break
 			}
 
-			if dataInTx.txId == 0 {
-				dataInTx.appendEntry(entry)
-				dataInTx.txId = entry.Meta.TxID
-				dataInTx.startOff = off
-			} else if dataInTx.isSameTx(entry) {
-				dataInTx.appendEntry(entry)
+			if hintBuf != nil {
+				hintBuf.Write(encodeHintRecord(entry, off))
 			}
 
-			if entry.Meta.Status == Committed {
-				err := parseDataInTx()
-				if err != nil {
-					return err
-				}
-				dataInTx.reset()
-				dataInTx.startOff = off
-			}
-
-			if !dataInTx.isSameTx(entry) {
-				dataInTx.reset()
-				dataInTx.startOff = off
+			if err := parseEntry(entry); err != nil {
+				return err
 			}
-
-			off += entry.Size()
 		}
 
 		if fID == db.MaxFileID {
@@ -710,22 +761,65 @@ func (db *DB) parseDataFiles(dataFileIds []int) (err error) {
 		return nil
 	}
 
+	// readEntriesFromHintFile replays the hint records of a sealed data file in the
+	// same way as the entries read from the data file.
+	var readEntriesFromHintFile = func(records []*hintRecord) error {
+		for _, record := range records {
+			off = record.dataPos
+			if err := parseEntry(record.entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if db.opt.EntryIdxMode == HintBPTSparseIdxMode {
 		dataFileIds = dataFileIds[len(dataFileIds)-1:]
 	}
 
+	hintLoaded := false
 	for _, dataID := range dataFileIds {
 		off = 0
 		fID = int64(dataID)
+
+		var hintBuf *bytes.Buffer
+		if db.isHintFileEnabled() {
+			if fID != db.MaxFileID {
+				if records, err := readHintFile(getHintPath(fID, db.opt.Dir)); err == nil {
+					if err := readEntriesFromHintFile(records); err != nil {
+						return err
+					}
+					hintLoaded = true
+					continue
+				}
+				// the hint file is missing or corrupted, rebuild it from the data file.
+				hintBuf = new(bytes.Buffer)
+			} else {
+				hintBuf = db.hintBuffer
+			}
+		}
+
 		dataPath := getDataPath(fID, db.opt.Dir)
 		f, err = newFileRecovery(dataPath, db.opt.BufferSizeOfRecovery)
 		if err != nil {
 			return err
 		}
-		err := readEntriesFromFile()
+		err := readEntriesFromFile(hintBuf)
 		if err != nil {
 			return err
 		}
+
+		if hintBuf != nil && fID != db.MaxFileID {
+			if err := writeHintFile(getHintPath(fID, db.opt.Dir), hintBuf.Bytes(), db.opt.SyncEnable); err != nil {
+				return err
+			}
+		}
+	}
+
+	if hintLoaded && db.opt.EntryIdxMode == HintKeyValAndRAMIdxMode {
+		if err = db.loadValuesForHintRecords(); err != nil {
+			return err
+		}
 	}
 
 	if HintBPTSparseIdxMode == db.opt.EntryIdxMode {
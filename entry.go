@@ -33,6 +33,8 @@ type (
 		Value  []byte
 		Bucket []byte
 		Meta   *MetaData
+
+		storedValue []byte // the compressed value stored in the data file, see Meta.Compression
 	}
 
 	// Hint represents the index of the key
@@ -55,6 +57,10 @@ type (
 		Status     uint16 // committed / uncommitted
 		Ds         uint16 // data structure
 		Crc        uint32
+
+		// Compression is the id of the compressor of the value, CompressionNone if the value is not
+		// compressed. It is stored in the high byte of the status, ValueSize is the stored size.
+		Compression uint8
 	}
 )
 
@@ -75,6 +81,8 @@ func (e *Entry) Size() int64 {
 //	|----------------------------------------------------------------------------------------------------------------|
 //	| uint32| uint64  |uint32 |  uint32 | uint16  | uint32| uint32 | uint16 | uint16 |uint64 |[]byte|[]byte | []byte |
 //	|----------------------------------------------------------------------------------------------------------------|
+//
+//	the low byte of status is the status, the high byte is the compression.
 func (e *Entry) Encode() []byte {
 	keySize := e.Meta.KeySize
 	valueSize := e.Meta.ValueSize
@@ -86,7 +94,7 @@ func (e *Entry) Encode() []byte {
 	// set bucket\key\value
 	copy(buf[DataEntryHeaderSize:(DataEntryHeaderSize+bucketSize)], e.Bucket)
 	copy(buf[(DataEntryHeaderSize+bucketSize):(DataEntryHeaderSize+bucketSize+keySize)], e.Key)
-	copy(buf[(DataEntryHeaderSize+bucketSize+keySize):(DataEntryHeaderSize+bucketSize+keySize+valueSize)], e.Value)
+	copy(buf[(DataEntryHeaderSize+bucketSize+keySize):(DataEntryHeaderSize+bucketSize+keySize+valueSize)], e.getStoredValue())
 
 	c32 := crc32.ChecksumIEEE(buf[4:])
 	binary.LittleEndian.PutUint32(buf[0:4], c32)
@@ -103,7 +111,7 @@ func (e *Entry) setEntryHeaderBuf(buf []byte) []byte {
 	binary.LittleEndian.PutUint16(buf[20:22], e.Meta.Flag)
 	binary.LittleEndian.PutUint32(buf[22:26], e.Meta.TTL)
 	binary.LittleEndian.PutUint32(buf[26:30], e.Meta.BucketSize)
-	binary.LittleEndian.PutUint16(buf[30:32], e.Meta.Status)
+	binary.LittleEndian.PutUint16(buf[30:32], e.Meta.Status|uint16(e.Meta.Compression)<<8)
 	binary.LittleEndian.PutUint16(buf[32:34], e.Meta.Ds)
 	binary.LittleEndian.PutUint64(buf[34:42], e.Meta.TxID)
 
@@ -123,7 +131,7 @@ func (e *Entry) GetCrc(buf []byte) uint32 {
 	crc := crc32.ChecksumIEEE(buf[4:])
 	crc = crc32.Update(crc, crc32.IEEETable, e.Bucket)
 	crc = crc32.Update(crc, crc32.IEEETable, e.Key)
-	crc = crc32.Update(crc, crc32.IEEETable, e.Value)
+	crc = crc32.Update(crc, crc32.IEEETable, e.getStoredValue())
 
 	return crc
 }
@@ -143,6 +151,9 @@ func (e *Entry) ParsePayload(data []byte) error {
 	// parse key
 	e.Key = data[keyLowBound:keyHighBound]
 	// parse value
+	if meta.Compression != CompressionNone {
+		return e.decompress(data[valueLowBound:valueHighBound])
+	}
 	e.Value = data[valueLowBound:valueHighBound]
 	return nil
 }
@@ -157,12 +168,13 @@ func (e *Entry) checkPayloadSize(size int64) error {
 
 // ParseMeta parse meta object to entry
 func (e *Entry) ParseMeta(buf []byte) error {
+	status := binary.LittleEndian.Uint16(buf[30:32])
 	e.Meta = NewMetaData().WithCrc(binary.LittleEndian.Uint32(buf[0:4])).
 		WithTimeStamp(binary.LittleEndian.Uint64(buf[4:12])).WithKeySize(binary.LittleEndian.Uint32(buf[12:16])).
 		WithValueSize(binary.LittleEndian.Uint32(buf[16:20])).WithFlag(binary.LittleEndian.Uint16(buf[20:22])).
 		WithTTL(binary.LittleEndian.Uint32(buf[22:26])).WithBucketSize(binary.LittleEndian.Uint32(buf[26:30])).
-		WithStatus(binary.LittleEndian.Uint16(buf[30:32])).WithDs(binary.LittleEndian.Uint16(buf[32:34])).
-		WithTxID(binary.LittleEndian.Uint64(buf[34:42]))
+		WithStatus(status & 0xff).WithDs(binary.LittleEndian.Uint16(buf[32:34])).
+		WithTxID(binary.LittleEndian.Uint64(buf[34:42])).WithCompression(uint8(status >> 8))
 	return nil
 }
 
@@ -317,6 +329,11 @@ func (meta *MetaData) WithCrc(crc uint32) *MetaData {
 	return meta
 }
 
+func (meta *MetaData) WithCompression(compression uint8) *MetaData {
+	meta.Compression = compression
+	return meta
+}
+
 // Entries represents entries
 type Entries []*Entry
 
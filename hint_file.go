@@ -0,0 +1,211 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// HintFileSuffix returns the hint file suffix.
+	HintFileSuffix = ".hint"
+
+	// HintRecordHeaderSize returns the header size of a hint record, crc and data position.
+	HintRecordHeaderSize = 12
+)
+
+// ErrHintFileCorrupted is returned when a hint file can not be decoded or fails its crc.
+var ErrHintFileCorrupted = errors.New("hint file corrupted")
+
+// hintRecord is a decoded hint file record, the entry carries the meta, bucket and key
+// of a data entry and dataPos is the offset of the entry in its data file.
+type hintRecord struct {
+	entry   *Entry
+	dataPos int64
+}
+
+// hintHasValue reports whether the value of the entry is kept in the hint file.
+// The values of the kv data structure are left in the data file, they are loaded
+// on demand, the other data structures need their values to rebuild the indexes.
+func hintHasValue(meta *MetaData) bool {
+	return meta.Ds != DataStructureTree
+}
+
+// encodeHintRecord returns the slice after the entry at the given dataPos be encoded as hint record.
+//
+//	the hint record stored format:
+//	|-----------------------------------------------------------------------|
+//	|  crc  | dataPos | entry header | bucket |  key  | value (not for kv)  |
+//	|-----------------------------------------------------------------------|
+//	| uint32| uint64  |   42 bytes   | []byte | []byte|       []byte        |
+//	|-----------------------------------------------------------------------|
+func encodeHintRecord(e *Entry, dataPos int64) []byte {
+	size := HintRecordHeaderSize + DataEntryHeaderSize + len(e.Bucket) + len(e.Key)
+	if hintHasValue(e.Meta) {
+		size += len(e.Value)
+	}
+
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint64(buf[4:12], uint64(dataPos))
+	e.setEntryHeaderBuf(buf[HintRecordHeaderSize : HintRecordHeaderSize+DataEntryHeaderSize])
+
+	off := HintRecordHeaderSize + DataEntryHeaderSize
+	off += copy(buf[off:], e.Bucket)
+	off += copy(buf[off:], e.Key)
+	if hintHasValue(e.Meta) {
+		copy(buf[off:], e.Value)
+	}
+
+	binary.LittleEndian.PutUint32(buf[0:4], crc32.ChecksumIEEE(buf[4:]))
+
+	return buf
+}
+
+// decodeHintRecords parses all the hint records in data, it fails as a whole
+// if any record is truncated or does not match its crc.
+func decodeHintRecords(data []byte) ([]*hintRecord, error) {
+	var records []*hintRecord
+
+	for off := 0; off < len(data); {
+		if len(data)-off < HintRecordHeaderSize+DataEntryHeaderSize {
+			return nil, ErrHintFileCorrupted
+		}
+
+		e := NewEntry()
+		headerOff := off + HintRecordHeaderSize
+		if err := e.ParseMeta(data[headerOff : headerOff+DataEntryHeaderSize]); err != nil {
+			return nil, err
+		}
+
+		payloadSize := int64(e.Meta.BucketSize) + int64(e.Meta.KeySize)
+		if hintHasValue(e.Meta) {
+			payloadSize += int64(e.Meta.ValueSize)
+		}
+
+		end := int64(headerOff+DataEntryHeaderSize) + payloadSize
+		if end > int64(len(data)) {
+			return nil, ErrHintFileCorrupted
+		}
+
+		if binary.LittleEndian.Uint32(data[off:off+4]) != crc32.ChecksumIEEE(data[off+4:end]) {
+			return nil, ErrHintFileCorrupted
+		}
+
+		payload := data[headerOff+DataEntryHeaderSize : end]
+		e.Bucket = payload[:e.Meta.BucketSize]
+		e.Key = payload[e.Meta.BucketSize : e.Meta.BucketSize+e.Meta.KeySize]
+		if hintHasValue(e.Meta) {
+			e.Value = payload[e.Meta.BucketSize+e.Meta.KeySize:]
+		} else if e.Meta.ValueSize == 0 {
+			e.Value = []byte{}
+		}
+
+		records = append(records, &hintRecord{
+			entry:   e,
+			dataPos: int64(binary.LittleEndian.Uint64(data[off+4 : off+12])),
+		})
+
+		off = int(end)
+	}
+
+	return records, nil
+}
+
+// readHintFile returns the hint records stored at given path.
+func readHintFile(path string) ([]*hintRecord, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeHintRecords(data)
+}
+
+// writeHintFile writes the encoded hint records to the given path. The records are written
+// to a temporary file first and renamed, so a hint file is either complete or missing.
+func writeHintFile(path string, data []byte, syncEnable bool) error {
+	tmpPath := path + ".tmp"
+	fd, err := os.OpenFile(filepath.Clean(tmpPath), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = fd.Write(data); err != nil {
+		_ = fd.Close()
+		return err
+	}
+
+	if syncEnable {
+		if err = fd.Sync(); err != nil {
+			_ = fd.Close()
+			return err
+		}
+	}
+
+	if err = fd.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// removeHintFile removes the hint file of the data file at given fID if it exists.
+func removeHintFile(fID int64, dir string) error {
+	if err := os.Remove(getHintPath(fID, dir)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isHintFileEnabled reports whether the db writes and loads hint files.
+// HintBPTSparseIdxMode keeps its own on disk indexes, so it does not use them.
+func (db *DB) isHintFileEnabled() bool {
+	return db.opt.HintFileEnable && db.opt.EntryIdxMode != HintBPTSparseIdxMode
+}
+
+// flushHintFile persists the hint records collected for the sealed data file at given fID.
+func (db *DB) flushHintFile(fID int64) error {
+	if !db.isHintFileEnabled() {
+		return nil
+	}
+
+	defer db.hintBuffer.Reset()
+
+	return writeHintFile(getHintPath(fID, db.opt.Dir), db.hintBuffer.Bytes(), db.opt.SyncEnable)
+}
+
+// loadValuesForHintRecords fills the values of the kv records which were rebuilt from
+// hint files, it is only needed in HintKeyValAndRAMIdxMode.
+func (db *DB) loadValuesForHintRecords() error {
+	for _, idx := range db.BTreeIdx {
+		for _, r := range idx.All() {
+			if r.V != nil {
+				continue
+			}
+
+			e, err := db.getEntryByHint(r.H)
+			if err != nil {
+				return err
+			}
+			r.V = e.Value
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintRecord_EncodeAndDecode(t *testing.T) {
+	kv := NewEntry().WithBucket([]byte("bucket")).WithKey([]byte("key")).WithValue([]byte("value")).
+		WithMeta(NewMetaData().WithBucketSize(6).WithKeySize(3).WithValueSize(5).WithDs(DataStructureTree).
+			WithFlag(DataSetFlag).WithTTL(10).WithTimeStamp(1000).WithTxID(1).WithStatus(Committed))
+	set := NewEntry().WithBucket([]byte("bucket")).WithKey([]byte("key")).WithValue([]byte("member")).
+		WithMeta(NewMetaData().WithBucketSize(6).WithKeySize(3).WithValueSize(6).WithDs(DataStructureSet).
+			WithFlag(DataSetFlag).WithTxID(2).WithStatus(Committed))
+
+	data := append(encodeHintRecord(kv, 0), encodeHintRecord(set, kv.Size())...)
+
+	records, err := decodeHintRecords(data)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	require.Equal(t, int64(0), records[0].dataPos)
+	require.Equal(t, []byte("key"), records[0].entry.Key)
+	require.Equal(t, []byte("bucket"), records[0].entry.Bucket)
+	require.Nil(t, records[0].entry.Value)
+	require.Equal(t, uint32(10), records[0].entry.Meta.TTL)
+	require.Equal(t, kv.Size(), records[0].entry.Size())
+
+	require.Equal(t, kv.Size(), records[1].dataPos)
+	require.Equal(t, []byte("member"), records[1].entry.Value)
+	require.Equal(t, DataStructureSet, records[1].entry.Meta.Ds)
+
+	t.Run("corrupted crc", func(t *testing.T) {
+		corrupted := append([]byte{}, data...)
+		corrupted[len(corrupted)-1] ^= 0xff
+		_, err := decodeHintRecords(corrupted)
+		require.Equal(t, ErrHintFileCorrupted, err)
+	})
+
+	t.Run("truncated record", func(t *testing.T) {
+		_, err := decodeHintRecords(data[:len(data)-3])
+		require.Equal(t, ErrHintFileCorrupted, err)
+	})
+}
+
+func TestDB_HintFile(t *testing.T) {
+	bucket := "bucket"
+
+	openWithHint := func(t *testing.T, dir string, mode EntryIdxMode) *DB {
+		opts := DefaultOptions
+		opts.Dir = dir
+		opts.SegmentSize = 8 * KB
+		opts.EntryIdxMode = mode
+		db, err := Open(opts)
+		require.NoError(t, err)
+		return db
+	}
+
+	for _, mode := range []EntryIdxMode{HintKeyValAndRAMIdxMode, HintKeyAndRAMIdxMode} {
+		dir, err := os.MkdirTemp("", "nutsdb-hint")
+		require.NoError(t, err)
+
+		db := openWithHint(t, dir, mode)
+		for i := 0; i < 300; i++ {
+			txPut(t, db, bucket, GetTestBytes(i), GetRandomBytes(24), Persistent, nil)
+			txPut(t, db, bucket, GetTestBytes(i), GetTestBytes(i), Persistent, nil)
+		}
+		for i := 0; i < 50; i++ {
+			txDel(t, db, bucket, GetTestBytes(i), nil)
+		}
+		txSAdd(t, db, bucket, []byte("set"), []byte("member"), nil)
+		txZAdd(t, db, bucket, []byte("zset"), []byte("member"), 1.5, nil)
+		txPush(t, db, bucket, []byte("list"), []byte("item"), nil, false)
+		require.NoError(t, db.Close())
+
+		maxFileID, _ := db.getMaxFileIDAndFileIDs()
+		require.Greater(t, maxFileID, int64(0))
+		for fID := int64(0); fID < maxFileID; fID++ {
+			records, err := readHintFile(getHintPath(fID, dir))
+			require.NoError(t, err)
+			require.NotEmpty(t, records)
+		}
+		require.NoFileExists(t, getHintPath(maxFileID, dir))
+
+		check := func(t *testing.T, db *DB) {
+			for i := 0; i < 50; i++ {
+				txGet(t, db, bucket, GetTestBytes(i), nil, ErrKeyNotFound)
+			}
+			for i := 50; i < 300; i++ {
+				txGet(t, db, bucket, GetTestBytes(i), GetTestBytes(i), nil)
+			}
+			txSIsMember(t, db, bucket, []byte("set"), []byte("member"), true)
+			txZScore(t, db, bucket, []byte("zset"), []byte("member"), 1.5, nil)
+			txRange(t, db, bucket, []byte("list"), 0, -1, 1)
+		}
+
+		db = openWithHint(t, dir, mode)
+		check(t, db)
+		require.NoError(t, db.Close())
+
+		// a corrupted hint file falls back to scanning the data file.
+		data, err := os.ReadFile(getHintPath(0, dir))
+		require.NoError(t, err)
+		data[len(data)/2] ^= 0xff
+		require.NoError(t, os.WriteFile(getHintPath(0, dir), data, 0o644))
+
+		db = openWithHint(t, dir, mode)
+		check(t, db)
+		require.NoError(t, db.Close())
+
+		// a missing hint file is rebuilt from the data file.
+		require.NoError(t, os.Remove(getHintPath(0, dir)))
+		db = openWithHint(t, dir, mode)
+		check(t, db)
+		require.NoError(t, db.Close())
+		require.FileExists(t, getHintPath(0, dir))
+
+		removeDir(dir)
+	}
+}
@@ -29,10 +29,12 @@ type IteratorOptions struct {
 }
 
 func NewIterator(tx *Tx, bucket string, options IteratorOptions) *Iterator {
+	tx.trackRead(DataStructureTree, bucket, nil)
+
 	iterator := &Iterator{
 		tx:      tx,
 		options: options,
-		iter:    tx.db.BTreeIdx[bucket].btree.Iter(),
+		iter:    tx.indexes().BTreeIdx[bucket].btree.Iter(),
 	}
 
 	if options.Reverse {
@@ -33,6 +33,7 @@ type List struct {
 	Items     map[string]*dll.List
 	TTL       map[string]uint32
 	TimeStamp map[string]uint64
+	version   uint64 // the snapshot epoch the list was created at
 }
 
 func NewList() *List {
@@ -43,6 +44,21 @@ func NewList() *List {
 	}
 }
 
+// clone returns a copy of the list which can be modified without affecting l.
+func (l *List) clone() *List {
+	list := NewList()
+	for key, items := range l.Items {
+		list.Items[key] = dll.New(items.Values()...)
+	}
+	for key, ttl := range l.TTL {
+		list.TTL[key] = ttl
+	}
+	for key, timestamp := range l.TimeStamp {
+		list.TimeStamp[key] = timestamp
+	}
+	return list
+}
+
 func (l *List) LPush(key string, r *Record) error {
 	return l.push(key, r, true)
 }
@@ -52,9 +68,8 @@ func (l *List) RPush(key string, r *Record) error {
 }
 
 func (l *List) push(key string, r *Record, isLeft bool) error {
-	if l.IsExpire(key) {
-		return ErrListNotFound
-	}
+	// an expired list is removed, the record starts a new one.
+	l.IsExpire(key)
 
 	list, ok := l.Items[key]
 	if !ok {
@@ -101,7 +116,7 @@ func (l *List) RPeek(key string) (*Record, error) {
 }
 
 func (l *List) peek(key string, isLeft bool) (*Record, error) {
-	if l.IsExpire(key) {
+	if l.isExpired(key) {
 		return nil, ErrListNotFound
 	}
 	list, ok := l.Items[key]
@@ -295,7 +310,22 @@ func (l *List) LRemByIndex(key string, indexes []int) error {
 	return nil
 }
 
+// IsExpire reports whether the list stored at key is expired, an expired list is removed.
 func (l *List) IsExpire(key string) bool {
+	if !l.isExpired(key) {
+		return false
+	}
+
+	delete(l.Items, key)
+	delete(l.TTL, key)
+	delete(l.TimeStamp, key)
+
+	return true
+}
+
+// isExpired reports whether the list stored at key is expired without removing it,
+// the lists read by a tx may be shared with a snapshot and must not be modified.
+func (l *List) isExpired(key string) bool {
 	if l == nil {
 		return false
 	}
@@ -311,15 +341,11 @@ func (l *List) IsExpire(key string) bool {
 		return false
 	}
 
-	delete(l.Items, key)
-	delete(l.TTL, key)
-	delete(l.TimeStamp, key)
-
 	return true
 }
 
 func (l *List) Size(key string) (int, error) {
-	if l.IsExpire(key) {
+	if l.isExpired(key) {
 		return 0, ErrListNotFound
 	}
 	if _, ok := l.Items[key]; !ok {
@@ -338,7 +364,7 @@ func (l *List) IsEmpty(key string) (bool, error) {
 }
 
 func (l *List) GetListTTL(key string) (uint32, error) {
-	if l.IsExpire(key) {
+	if l.isExpired(key) {
 		return 0, ErrListNotFound
 	}
 
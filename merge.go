@@ -104,6 +104,9 @@ func (db *DB) merge() error {
 
 	db.ActiveFile.fileID = db.MaxFileID
 
+	// the old active file is merged and removed below, its hint records are dropped.
+	db.hintBuffer.Reset()
+
 	db.mu.Unlock()
 
 	mergingPath := make([]string, len(pendingMergeFIds))
@@ -130,26 +133,7 @@ func (db *DB) merge() error {
 					continue
 				}
 
-				// Due to the lack of concurrency safety in the index,
-				// there is a possibility that a race condition might occur when the merge goroutine reads the index,
-				// while a transaction is being committed, causing modifications to the index.
-				// To address this issue, we need to use a transaction to perform this operation.
-				err := db.Update(func(tx *Tx) error {
-					// check if we have a new entry with same key and bucket
-					if ok := db.isPendingMergeEntry(entry); ok {
-						return tx.put(
-							string(entry.Bucket),
-							entry.Key,
-							entry.Value,
-							entry.Meta.TTL,
-							entry.Meta.Flag,
-							entry.Meta.Timestamp,
-							entry.Meta.Ds,
-						)
-					}
-					return nil
-				})
-
+				err := db.rewriteForMerge(entry)
 				if err != nil {
 					_ = fr.release()
 					return err
@@ -181,6 +165,9 @@ func (db *DB) merge() error {
 		mergingPath[i] = path
 	}
 
+	// the snapshots taken before the entries were rewritten may still read the merged files.
+	db.waitForSnapshots(db.currentEpoch())
+
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
@@ -188,6 +175,9 @@ func (db *DB) merge() error {
 		if err := os.Remove(mergingPath[i]); err != nil {
 			return fmt.Errorf("when merge err: %s", err)
 		}
+		if err := removeHintFile(int64(pendingMergeFIds[i]), db.opt.Dir); err != nil {
+			return fmt.Errorf("when merge err: %s", err)
+		}
 	}
 
 	return nil
@@ -220,16 +210,50 @@ func (db *DB) mergeWorker() {
 	}
 }
 
+// rewriteForMerge writes the entry to the active file if it is still valid.
+//
+// Due to the lack of concurrency safety in the index,
+// there is a possibility that a race condition might occur when the merge goroutine reads the index,
+// while a transaction is being committed, causing modifications to the index.
+// To address this issue, we need to use a transaction to perform this operation.
+// The tx is retried if the entry is modified by a concurrent tx, while the concurrent
+// transactions do not conflict with the rewrite which does not change the data.
+func (db *DB) rewriteForMerge(entry *Entry) error {
+	for {
+		err := db.Update(func(tx *Tx) error {
+			tx.ignoreConflictWrites = true
+
+			// check if we have a new entry with same key and bucket
+			if ok := tx.isPendingMergeEntry(entry); ok {
+				return tx.put(
+					string(entry.Bucket),
+					entry.Key,
+					entry.Value,
+					entry.Meta.TTL,
+					entry.Meta.Flag,
+					entry.Meta.Timestamp,
+					entry.Meta.Ds,
+				)
+			}
+			return nil
+		})
+		if err != ErrConflict {
+			return err
+		}
+	}
+}
+
 // 判断entry是否是有效的entry以及在索引中是否查找得到，如老的entry就不是有效的，因为有该key有更新了
-func (db *DB) isPendingMergeEntry(entry *Entry) bool {
+func (tx *Tx) isPendingMergeEntry(entry *Entry) bool {
 	if entry.Meta.Ds == DataStructureTree {
-		idx, exist := db.BTreeIdx[string(entry.Bucket)]
+		tx.trackRead(DataStructureTree, string(entry.Bucket), entry.Key)
+		idx, exist := tx.indexes().BTreeIdx[string(entry.Bucket)]
 		if exist {
 			r, ok := idx.Find(entry.Key)
 			if ok && r.H.Meta.Flag == DataSetFlag {
 				if r.IsExpired() {
-					db.tm.del(string(entry.Bucket), string(entry.Key))
-					db.BTreeIdx[string(entry.Bucket)].Delete(entry.Key)
+					// the expired key is deleted, its entry is removed with the merged file.
+					tx.putDeleteLog(string(entry.Bucket), entry.Key, nil, Persistent, DataDeleteFlag, uint64(time.Now().Unix()), DataStructureTree)
 					return false
 				}
 				// 表明entry是旧的数据，事务id大的表明是最新的数据
@@ -242,7 +266,8 @@ func (db *DB) isPendingMergeEntry(entry *Entry) bool {
 	}
 
 	if entry.Meta.Ds == DataStructureSet {
-		setIdx, exist := db.SetIdx[string(entry.Bucket)]
+		tx.trackRead(DataStructureSet, string(entry.Bucket), entry.Key)
+		setIdx, exist := tx.indexes().SetIdx[string(entry.Bucket)]
 		if exist {
 			isMember, err := setIdx.SIsMember(string(entry.Key), entry.Value)
 			if err != nil {
@@ -260,7 +285,8 @@ func (db *DB) isPendingMergeEntry(entry *Entry) bool {
 		if len(keyAndScore) == 2 {
 			key := keyAndScore[0]
 			score, _ := strconv2.StrToFloat64(keyAndScore[1])
-			sortedSetIdx, exist := db.SortedSetIdx[string(entry.Bucket)]
+			tx.trackRead(DataStructureSortedSet, string(entry.Bucket), []byte(key))
+			sortedSetIdx, exist := tx.indexes().SortedSetIdx[string(entry.Bucket)]
 			if exist {
 				s, err := sortedSetIdx.ZScore(key, entry.Value)
 				if err != nil {
@@ -121,6 +121,18 @@ type Options struct {
 	// TimeWheel means use the time wheel, You can use it when you need high performance or low memory usage
 	// TimeHeap means use the time heap, You can use it when you need to delete precisely or memory usage will be high
 	ExpiredDeleteType ExpiredDeleteType
+
+	// HintFileEnable represents if write a hint file for every sealed data file.
+	// With hint files, Open loads the indexes from them instead of scanning the data files.
+	HintFileEnable bool
+
+	// Compressor represents the compressor of the values written by Put, nil means the values are not compressed.
+	// The entries written before are still readable after the compressor is changed.
+	Compressor Compressor
+
+	// BucketCompressors overrides Compressor for the buckets in it, a nil compressor disables the
+	// compression of the bucket.
+	BucketCompressors map[string]Compressor
 }
 
 const (
@@ -149,6 +161,7 @@ var DefaultOptions = func() Options {
 		MaxBatchSize:      (15 * defaultSegmentSize / 4) / 100,
 		MaxBatchCount:     (15 * defaultSegmentSize / 4) / 100 / 100,
 		ExpiredDeleteType: TimeWheel,
+		HintFileEnable:    true,
 	}
 }()
 
@@ -243,3 +256,24 @@ func WithLessFunc(lessFunc LessFunc) Option {
 		opt.LessFunc = lessFunc
 	}
 }
+
+func WithHintFileEnable(enable bool) Option {
+	return func(opt *Options) {
+		opt.HintFileEnable = enable
+	}
+}
+
+func WithCompressor(compressor Compressor) Option {
+	return func(opt *Options) {
+		opt.Compressor = compressor
+	}
+}
+
+func WithBucketCompressor(bucket string, compressor Compressor) Option {
+	return func(opt *Options) {
+		if opt.BucketCompressors == nil {
+			opt.BucketCompressors = make(map[string]Compressor)
+		}
+		opt.BucketCompressors[bucket] = compressor
+	}
+}
@@ -30,10 +30,9 @@ var (
 	ErrMemberEmpty = errors.New("item empty")
 )
 
-var fnvHash = fnv.New32a()
-
 type Set struct {
-	M map[string]map[uint32]*Record
+	M       map[string]map[uint32]*Record
+	version uint64 // the snapshot epoch the set was created at
 }
 
 func NewSet() *Set {
@@ -42,6 +41,19 @@ func NewSet() *Set {
 	}
 }
 
+// clone returns a copy of the set which can be modified without affecting s.
+func (s *Set) clone() *Set {
+	set := &Set{M: make(map[string]map[uint32]*Record, len(s.M))}
+	for key, members := range s.M {
+		m := make(map[uint32]*Record, len(members))
+		for hash, record := range members {
+			m[hash] = record
+		}
+		set.M[key] = m
+	}
+	return set
+}
+
 // SAdd adds the specified members to the set stored at key.
 func (s *Set) SAdd(key string, values [][]byte, records []*Record) error {
 	set, ok := s.M[key]
@@ -260,11 +272,10 @@ func (s *Set) SUnion(key1, key2 string) ([]*Record, error) {
 }
 
 func getFnv32(value []byte) (uint32, error) {
+	fnvHash := fnv.New32a()
 	_, err := fnvHash.Write(value)
 	if err != nil {
 		return 0, err
 	}
-	hash := fnvHash.Sum32()
-	fnvHash.Reset()
-	return hash, nil
+	return fnvHash.Sum32(), nil
 }
@@ -0,0 +1,218 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"math"
+	"sync"
+)
+
+// snapshot is a consistent view of the in-memory indexes.
+//
+// A tx takes a snapshot when it begins and reads from it without holding db.mu, so it
+// runs concurrently with the other transactions. The BTree of every bucket is a
+// copy-on-write copy, the set, sorted set and list indexes are shared with the db and
+// a writer copies the one it modifies first if it may still be referenced by a live
+// snapshot, see needCopy.
+type snapshot struct {
+	epoch        uint64
+	readTs       uint64 // the commit ts of the latest write tx seen by the snapshot
+	BTreeIdx     BTreeIdx
+	SetIdx       SetIdx
+	SortedSetIdx SortedSetIdx
+	Index        *index
+}
+
+// snapshots tracks the live snapshots of the db.
+//
+// The epoch is bumped every time a snapshot is taken. An index structure is stamped
+// with the epoch at which a writer created or copied it, so it is only referenced by
+// the live snapshots taken at a later epoch.
+type snapshots struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	epoch uint64
+	live  map[uint64]int // the number of live snapshots by epoch
+}
+
+func newSnapshots() *snapshots {
+	s := &snapshots{live: make(map[uint64]int)}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// isSnapshotReadEnabled reports whether the transactions read from a snapshot.
+// The on disk indexes of HintBPTSparseIdxMode can not be copied, the transactions
+// still lock the db in this mode.
+func (db *DB) isSnapshotReadEnabled() bool {
+	return db.opt.EntryIdxMode != HintBPTSparseIdxMode
+}
+
+// takeSnapshot returns a snapshot of the in-memory indexes, it must be released by
+// releaseSnapshot when the tx is done.
+func (db *DB) takeSnapshot(writable bool) (*snapshot, error) {
+	db.idxLock.RLock()
+	defer db.idxLock.RUnlock()
+
+	if db.closed {
+		return nil, ErrDBClosed
+	}
+
+	s := &snapshot{
+		readTs:       db.conflicts.begin(writable),
+		BTreeIdx:     make(BTreeIdx, len(db.BTreeIdx)),
+		SetIdx:       make(SetIdx, len(db.SetIdx)),
+		SortedSetIdx: make(SortedSetIdx, len(db.SortedSetIdx)),
+		Index:        &index{list: make(ListIdx, len(db.Index.list))},
+	}
+
+	for bucket, bt := range db.BTreeIdx {
+		s.BTreeIdx[bucket] = bt.Copy()
+	}
+	for bucket, set := range db.SetIdx {
+		s.SetIdx[bucket] = set
+	}
+	for bucket, sortedSet := range db.SortedSetIdx {
+		s.SortedSetIdx[bucket] = sortedSet
+	}
+	for bucket, l := range db.Index.list {
+		s.Index.list[bucket] = l
+	}
+
+	db.snapshots.mu.Lock()
+	db.snapshots.epoch++
+	s.epoch = db.snapshots.epoch
+	db.snapshots.live[s.epoch]++
+	db.snapshots.mu.Unlock()
+
+	return s, nil
+}
+
+// releaseSnapshot releases a snapshot taken by takeSnapshot.
+func (db *DB) releaseSnapshot(s *snapshot) {
+	db.snapshots.mu.Lock()
+	defer db.snapshots.mu.Unlock()
+
+	if db.snapshots.live[s.epoch]--; db.snapshots.live[s.epoch] <= 0 {
+		delete(db.snapshots.live, s.epoch)
+	}
+	db.snapshots.cond.Broadcast()
+}
+
+// waitForSnapshots blocks until the snapshots taken at or before the given epoch are released.
+func (db *DB) waitForSnapshots(epoch uint64) {
+	db.snapshots.mu.Lock()
+	defer db.snapshots.mu.Unlock()
+
+	for {
+		waiting := false
+		for e := range db.snapshots.live {
+			if e <= epoch {
+				waiting = true
+				break
+			}
+		}
+		if !waiting {
+			return
+		}
+		db.snapshots.cond.Wait()
+	}
+}
+
+// waitForAllSnapshots blocks until all the live snapshots are released.
+func (db *DB) waitForAllSnapshots() {
+	db.waitForSnapshots(math.MaxUint64)
+}
+
+// currentEpoch returns the epoch of the latest snapshot.
+func (db *DB) currentEpoch() uint64 {
+	db.snapshots.mu.Lock()
+	defer db.snapshots.mu.Unlock()
+
+	return db.snapshots.epoch
+}
+
+// needCopy reports whether the index structure stamped with version may be referenced
+// by a live snapshot, so the writer must modify a copy of it. It also returns the version
+// to stamp the structure the writer modifies with. It is called with db.idxLock held.
+func (db *DB) needCopy(version uint64) (bool, uint64) {
+	db.snapshots.mu.Lock()
+	defer db.snapshots.mu.Unlock()
+
+	for epoch := range db.snapshots.live {
+		if epoch > version {
+			return true, db.snapshots.epoch
+		}
+	}
+
+	return false, db.snapshots.epoch
+}
+
+// setForUpdate returns the set of the bucket for the committing tx to modify.
+func (db *DB) setForUpdate(bucket string) *Set {
+	set, ok := db.SetIdx[bucket]
+	if !ok {
+		set = NewSet()
+		set.version = db.currentEpoch()
+		db.SetIdx[bucket] = set
+		return set
+	}
+
+	if shared, version := db.needCopy(set.version); shared {
+		set = set.clone()
+		set.version = version
+		db.SetIdx[bucket] = set
+	}
+
+	return set
+}
+
+// sortedSetForUpdate returns the sorted set of the bucket for the committing tx to modify.
+func (db *DB) sortedSetForUpdate(bucket string) *SortedSet {
+	sortedSet, ok := db.SortedSetIdx[bucket]
+	if !ok {
+		sortedSet = NewSortedSet(db)
+		sortedSet.version = db.currentEpoch()
+		db.SortedSetIdx[bucket] = sortedSet
+		return sortedSet
+	}
+
+	if shared, version := db.needCopy(sortedSet.version); shared {
+		sortedSet = sortedSet.clone()
+		sortedSet.version = version
+		db.SortedSetIdx[bucket] = sortedSet
+	}
+
+	return sortedSet
+}
+
+// listForUpdate returns the list of the bucket for the committing tx to modify.
+func (db *DB) listForUpdate(bucket string) *List {
+	l, ok := db.Index.list[bucket]
+	if !ok {
+		l = NewList()
+		l.version = db.currentEpoch()
+		db.Index.list[bucket] = l
+		return l
+	}
+
+	if shared, version := db.needCopy(l.version); shared {
+		l = l.clone()
+		l.version = version
+		db.Index.list[bucket] = l
+	}
+
+	return l
+}
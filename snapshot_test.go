@@ -0,0 +1,195 @@
+// Copyright 2023 The nutsdb Author. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nutsdb
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTx_SnapshotRead(t *testing.T) {
+	bucket := "bucket"
+
+	withDefaultDB(t, func(t *testing.T, db *DB) {
+		txPut(t, db, bucket, []byte("key"), []byte("v1"), Persistent, nil)
+		txSAdd(t, db, bucket, []byte("set"), []byte("m1"), nil)
+		txZAdd(t, db, bucket, []byte("zset"), []byte("m1"), 1, nil)
+		txPush(t, db, bucket, []byte("list"), []byte("i1"), nil, false)
+
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+
+		// the writers are not blocked by the open read-only tx.
+		txPut(t, db, bucket, []byte("key"), []byte("v2"), Persistent, nil)
+		txPut(t, db, bucket, []byte("new"), []byte("v"), Persistent, nil)
+		txSAdd(t, db, bucket, []byte("set"), []byte("m2"), nil)
+		txSRem(t, db, bucket, []byte("set"), []byte("m1"), nil)
+		txZAdd(t, db, bucket, []byte("zset"), []byte("m2"), 2, nil)
+		txZRem(t, db, bucket, []byte("zset"), []byte("m1"), nil)
+		txPush(t, db, bucket, []byte("list"), []byte("i2"), nil, false)
+		txPop(t, db, bucket, []byte("list"), []byte("i1"), nil, true)
+
+		e, err := tx.Get(bucket, []byte("key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), e.Value)
+
+		_, err = tx.Get(bucket, []byte("new"))
+		require.Equal(t, ErrKeyNotFound, err)
+
+		members, err := tx.SMembers(bucket, []byte("set"))
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("m1")}, members)
+
+		score, err := tx.ZScore(bucket, []byte("zset"), []byte("m1"))
+		require.NoError(t, err)
+		require.Equal(t, float64(1), score)
+		card, err := tx.ZCard(bucket, []byte("zset"))
+		require.NoError(t, err)
+		require.Equal(t, 1, card)
+
+		items, err := tx.LRange(bucket, []byte("list"), 0, -1)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("i1")}, items)
+
+		require.NoError(t, tx.Commit())
+
+		txGet(t, db, bucket, []byte("key"), []byte("v2"), nil)
+		txGet(t, db, bucket, []byte("new"), []byte("v"), nil)
+		txSIsMember(t, db, bucket, []byte("set"), []byte("m1"), false)
+		txSIsMember(t, db, bucket, []byte("set"), []byte("m2"), true)
+		txZScore(t, db, bucket, []byte("zset"), []byte("m2"), 2, nil)
+		txRange(t, db, bucket, []byte("list"), 0, -1, 1)
+	})
+}
+
+func TestTx_SnapshotReadConsistency(t *testing.T) {
+	bucket := "bucket"
+	keys := [][]byte{GetTestBytes(0), GetTestBytes(1), GetTestBytes(2)}
+
+	withDefaultDB(t, func(t *testing.T, db *DB) {
+		write := func(i int) error {
+			return db.Update(func(tx *Tx) error {
+				for _, key := range keys {
+					if err := tx.Put(bucket, key, GetTestBytes(i), Persistent); err != nil {
+						return err
+					}
+					if err := tx.SAdd(bucket, key, GetTestBytes(i)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+		require.NoError(t, write(0))
+
+		var (
+			wg   sync.WaitGroup
+			done = make(chan struct{})
+		)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done)
+			for i := 1; i <= 200; i++ {
+				require.NoError(t, write(i))
+			}
+		}()
+
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					err := db.View(func(tx *Tx) error {
+						var value []byte
+						var card int
+						for i, key := range keys {
+							e, err := tx.Get(bucket, key)
+							if err != nil {
+								return err
+							}
+							members, err := tx.SMembers(bucket, key)
+							if err != nil {
+								return err
+							}
+							if i == 0 {
+								value, card = e.Value, len(members)
+							}
+							// a tx is never seen half applied.
+							require.Equal(t, value, e.Value)
+							require.Equal(t, card, len(members))
+						}
+						return nil
+					})
+					require.NoError(t, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestDB_MergeWaitsForSnapshots(t *testing.T) {
+	bucket := "bucket"
+
+	opts := DefaultOptions
+	opts.SegmentSize = 8 * KB
+	opts.EntryIdxMode = HintKeyAndRAMIdxMode
+	runNutsDBTest(t, &opts, func(t *testing.T, db *DB) {
+		for i := 0; i < 100; i++ {
+			txPut(t, db, bucket, GetTestBytes(i), GetRandomBytes(24), Persistent, nil)
+			txPut(t, db, bucket, GetTestBytes(i), GetTestBytes(i), Persistent, nil)
+		}
+
+		tx, err := db.Begin(false)
+		require.NoError(t, err)
+
+		merged := make(chan error)
+		go func() {
+			merged <- db.Merge()
+		}()
+
+		select {
+		case err := <-merged:
+			t.Fatalf("merge returned %v before the snapshot was released", err)
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		// the data files referenced by the snapshot are still readable.
+		for i := 0; i < 100; i++ {
+			e, err := tx.Get(bucket, GetTestBytes(i))
+			require.NoError(t, err)
+			require.Equal(t, GetTestBytes(i), e.Value)
+		}
+		require.NoError(t, tx.Commit())
+
+		require.NoError(t, <-merged)
+
+		for i := 0; i < 100; i++ {
+			txGet(t, db, bucket, GetTestBytes(i), GetTestBytes(i), nil)
+		}
+	})
+}
@@ -37,8 +37,9 @@ const (
 )
 
 type SortedSet struct {
-	db *DB
-	M  map[string]*SkipList
+	db      *DB
+	M       map[string]*SkipList
+	version uint64 // the snapshot epoch the sorted set was created at
 }
 
 func NewSortedSet(db *DB) *SortedSet {
@@ -48,6 +49,15 @@ func NewSortedSet(db *DB) *SortedSet {
 	}
 }
 
+// clone returns a copy of the sorted set which can be modified without affecting z.
+func (z *SortedSet) clone() *SortedSet {
+	sortedSet := NewSortedSet(z.db)
+	for key, sl := range z.M {
+		sortedSet.M[key] = sl.clone()
+	}
+	return sortedSet
+}
+
 func (z *SortedSet) ZAdd(key string, score SCORE, value []byte, record *Record) error {
 	sortedSet, ok := z.M[key]
 	if !ok {
@@ -332,6 +342,39 @@ func newSkipList(db *DB) *SkipList {
 	return skipList
 }
 
+// clone returns a copy of the skip list with the same structure, the nodes are copied
+// and share the records with sl.
+func (sl *SkipList) clone() *SkipList {
+	skipList := &SkipList{
+		db:     sl.db,
+		length: sl.length,
+		level:  sl.level,
+		dict:   make(map[uint32]*SkipListNode, len(sl.dict)),
+	}
+
+	nodes := make(map[*SkipListNode]*SkipListNode, len(sl.dict)+1)
+	copyNode := func(x *SkipListNode) *SkipListNode {
+		node := createNode(len(x.level), x.score, x.hash, x.record)
+		nodes[x] = node
+		return node
+	}
+
+	skipList.header = copyNode(sl.header)
+	for x := sl.header.level[0].forward; x != nil; x = x.level[0].forward {
+		skipList.dict[x.hash] = copyNode(x)
+	}
+
+	for x, node := range nodes {
+		node.backward = nodes[x.backward]
+		for i := range x.level {
+			node.level[i] = SkipListLevel{forward: nodes[x.level[i].forward], span: x.level[i].span}
+		}
+	}
+	skipList.tail = nodes[sl.tail]
+
+	return skipList
+}
+
 func (sl *SkipList) cmp(r1 *Record, r2 *Record) int {
 	val1, _ := sl.db.getValueByRecord(r1)
 	val2, _ := sl.db.getValueByRecord(r2)
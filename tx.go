@@ -90,6 +90,9 @@ type Tx struct {
 	pendingWrites          []*Entry
 	ReservedStoreTxIDIdxes map[int64]*BPTree
 	size                   int64
+	snapshot               *snapshot                // the indexes the tx reads from, nil if it locks the db instead
+	reads                  map[conflictKey]struct{} // the data read by an optimistic tx
+	ignoreConflictWrites   bool                     // the writes do not change the data, other transactions do not conflict with them
 }
 
 type txnCb struct {
@@ -115,10 +118,13 @@ func runTxnCallback(cb *txnCb) {
 }
 
 // Begin opens a new transaction.
-// Multiple read-only transactions can be opened at the same time but there can
-// only be one read/write transaction at a time. Attempting to open a read/write
-// transactions while another one is in progress will result in blocking until
-// the current read/write transaction is completed.
+// Transactions read from a snapshot of the indexes taken when they begin, so multiple
+// read-only and read/write transactions can run at the same time. A read/write
+// transaction fails to commit with ErrConflict if the data it read or wrote was
+// modified by another transaction committed after it began, it can be retried.
+// In HintBPTSparseIdxMode there can only be one read/write transaction at a time,
+// attempting to open a read/write transaction while another one is in progress
+// will result in blocking until the current read/write transaction is completed.
 // All transactions must be closed by calling Commit() or Rollback() when done.
 func (db *DB) Begin(writable bool) (tx *Tx, err error) {
 	tx, err = newTx(db, writable)
@@ -126,6 +132,15 @@ func (db *DB) Begin(writable bool) (tx *Tx, err error) {
 		return nil, err
 	}
 
+	if db.isSnapshotReadEnabled() {
+		if tx.snapshot, err = db.takeSnapshot(writable); err != nil {
+			tx.setStatusClosed()
+			return nil, err
+		}
+		tx.setStatusRunning()
+		return
+	}
+
 	tx.lock()
 	tx.setStatusRunning()
 	if db.closed {
@@ -204,9 +219,11 @@ func (tx *Tx) checkSize() error {
 
 // getTxID returns the tx id.
 func (tx *Tx) getTxID() (id uint64, err error) {
-	node, err := snowflake.NewNode(tx.db.opt.NodeNum)
-	if err != nil {
-		return 0, err
+	node := tx.db.snowflakeNode
+	if node == nil {
+		if node, err = snowflake.NewNode(tx.db.opt.NodeNum); err != nil {
+			return 0, err
+		}
 	}
 
 	id = uint64(node.Generate().Int64())
@@ -259,6 +276,19 @@ func (tx *Tx) Commit() (err error) {
 		return nil
 	}
 
+	if tx.isOptimistic() {
+		tx.db.mu.Lock()
+		defer tx.db.mu.Unlock()
+
+		if tx.db.closed {
+			return ErrDBClosed
+		}
+
+		if tx.hasConflict() {
+			return ErrConflict
+		}
+	}
+
 	lastIndex := writesLen - 1
 	countFlag := CountFlagEnabled
 	if tx.db.isMerging {
@@ -268,6 +298,13 @@ func (tx *Tx) Commit() (err error) {
 	buff := tx.allocCommitBuffer()
 	defer tx.db.commitBuffer.Reset()
 
+	// the records are added to the indexes after all the entries are written.
+	records := make([]*Record, 0, writesLen)
+
+	// hint records of the entries in buff, they are moved to the hint buffer of the db once buff is written.
+	var hintBuff []byte
+	hintFileEnabled := tx.db.isHintFileEnabled()
+
 	// 依次写entry
 	for i := 0; i < writesLen; i++ {
 		entry := tx.pendingWrites[i]
@@ -285,6 +322,8 @@ func (tx *Tx) Commit() (err error) {
 				return err
 			}
 			buff.Reset()
+			tx.db.hintBuffer.Write(hintBuff)
+			hintBuff = hintBuff[:0]
 
 			if err := tx.rotateActiveFile(); err != nil {
 				return err
@@ -305,11 +344,16 @@ func (tx *Tx) Commit() (err error) {
 			return err
 		}
 
+		if hintFileEnabled {
+			hintBuff = append(hintBuff, encodeHintRecord(entry, offset)...)
+		}
+
 		// 最后一个entry进行批量一次写入文件，写入到db.ActiveFile，而不是每个entry写入文件，提高写入性能
 		if i == lastIndex {
 			if _, err := tx.writeData(buff.Bytes()); err != nil {
 				return err
 			}
+			tx.db.hintBuffer.Write(hintBuff)
 		}
 
 		if tx.db.opt.EntryIdxMode == HintBPTSparseIdxMode {
@@ -334,26 +378,46 @@ func (tx *Tx) Commit() (err error) {
 		hint := NewHint().WithKey(entry.Key).WithFileId(tx.db.ActiveFile.fileID).WithMeta(entry.Meta).WithDataPos(uint64(offset))
 		record := NewRecord().WithBucket(bucket).WithValue(entry.Value).WithHint(hint)
 
-		if entry.Meta.Ds == DataStructureTree {
+		if entry.Meta.Ds == DataStructureTree && tx.db.opt.EntryIdxMode == HintBPTSparseIdxMode {
+			// the sparse index of the active file is written when the file is rotated, so it is built right away.
 			tx.buildTreeIdx(record, countFlag)
+			continue
 		}
 
-		if entry.Meta.Ds == DataStructureList {
-			tx.buildListIdx(record)
-		}
+		records = append(records, record)
+	}
 
-		if entry.Meta.Ds == DataStructureSet {
-			tx.buildSetIdx(record)
-		}
+	tx.buildIdxes(records, countFlag)
+
+	return nil
+}
 
-		if entry.Meta.Ds == DataStructureSortedSet {
+// buildIdxes adds the records of the committed tx to the in-memory indexes at once,
+// so a snapshot sees either all or none of the changes of the tx.
+func (tx *Tx) buildIdxes(records []*Record, countFlag bool) {
+	tx.db.idxLock.Lock()
+	defer tx.db.idxLock.Unlock()
+
+	for _, record := range records {
+		switch record.H.Meta.Ds {
+		case DataStructureTree:
+			tx.buildTreeIdx(record, countFlag)
+		case DataStructureList:
+			tx.buildListIdx(record)
+		case DataStructureSet:
+			tx.buildSetIdx(record)
+		case DataStructureSortedSet:
 			tx.buildSortedSetIdx(record)
 		}
 	}
 
 	tx.buildNotDSIdxes()
 
-	return nil
+	if tx.ignoreConflictWrites {
+		tx.db.conflicts.commit(nil)
+	} else {
+		tx.db.conflicts.commit(tx.conflictWrites())
+	}
 }
 
 func (tx *Tx) allocCommitBuffer() *bytes.Buffer {
@@ -558,7 +622,7 @@ func (tx *Tx) buildTreeIdx(record *Record, countFlag bool) {
 				tx.db.tm.del(bucket, string(key))
 			}
 
-			hint := NewHint().WithFileId(tx.db.ActiveFile.fileID).WithKey(key).WithMeta(meta).WithDataPos(offset)
+			hint := NewHint().WithFileId(record.H.FileID).WithKey(key).WithMeta(meta).WithDataPos(offset)
 			tx.db.BTreeIdx[bucket].Insert(key, value, hint)
 		} else if meta.Flag == DataDeleteFlag {
 			tx.db.tm.del(bucket, string(key))
@@ -572,16 +636,14 @@ func (tx *Tx) buildSetIdx(record *Record) {
 
 	tx.db.resetRecordByMode(record)
 
-	if _, ok := tx.db.SetIdx[bucket]; !ok {
-		tx.db.SetIdx[bucket] = NewSet()
-	}
+	set := tx.db.setForUpdate(bucket)
 
 	if meta.Flag == DataDeleteFlag {
-		_ = tx.db.SetIdx[bucket].SRem(string(key), value)
+		_ = set.SRem(string(key), value)
 	}
 
 	if meta.Flag == DataSetFlag {
-		_ = tx.db.SetIdx[bucket].SAdd(string(key), [][]byte{value}, []*Record{record})
+		_ = set.SAdd(string(key), [][]byte{value}, []*Record{record})
 	}
 }
 
@@ -590,27 +652,25 @@ func (tx *Tx) buildSortedSetIdx(record *Record) {
 
 	tx.db.resetRecordByMode(record)
 
-	if _, ok := tx.db.SortedSetIdx[bucket]; !ok {
-		tx.db.SortedSetIdx[bucket] = NewSortedSet(tx.db)
-	}
+	sortedSet := tx.db.sortedSetForUpdate(bucket)
 
 	switch meta.Flag {
 	case DataZAddFlag:
 		keyAndScore := strings.Split(string(key), SeparatorForZSetKey)
 		key := keyAndScore[0]
 		score, _ := strconv2.StrToFloat64(keyAndScore[1])
-		_ = tx.db.SortedSetIdx[bucket].ZAdd(key, SCORE(score), value, record)
+		_ = sortedSet.ZAdd(key, SCORE(score), value, record)
 	case DataZRemFlag:
-		_, _ = tx.db.SortedSetIdx[bucket].ZRem(string(key), value)
+		_, _ = sortedSet.ZRem(string(key), value)
 	case DataZRemRangeByRankFlag:
 		startAndEnd := strings.Split(string(value), SeparatorForZSetKey)
 		start, _ := strconv2.StrToInt(startAndEnd[0])
 		end, _ := strconv2.StrToInt(startAndEnd[1])
-		_ = tx.db.SortedSetIdx[bucket].ZRemRangeByRank(string(key), start, end)
+		_ = sortedSet.ZRemRangeByRank(string(key), start, end)
 	case DataZPopMaxFlag:
-		_, _, _ = tx.db.SortedSetIdx[bucket].ZPopMax(string(key))
+		_, _, _ = sortedSet.ZPopMax(string(key))
 	case DataZPopMinFlag:
-		_, _, _ = tx.db.SortedSetIdx[bucket].ZPopMin(string(key))
+		_, _, _ = sortedSet.ZPopMin(string(key))
 	}
 }
 
@@ -621,7 +681,7 @@ func (tx *Tx) buildListIdx(record *Record) {
 	// 非kv索引模式时将value清空
 	tx.db.resetRecordByMode(record)
 
-	l := tx.db.Index.getList(bucket)
+	l := tx.db.listForUpdate(bucket)
 
 	if IsExpired(meta.TTL, meta.Timestamp) {
 		return
@@ -687,6 +747,10 @@ func (tx *Tx) rotateActiveFile() error {
 		return err
 	}
 
+	if err := tx.db.flushHintFile(fID); err != nil {
+		return err
+	}
+
 	if tx.db.opt.EntryIdxMode == HintBPTSparseIdxMode {
 		tx.db.ActiveBPTreeIdx.Filepath = getBPTPath(fID, tx.db.opt.Dir)
 		tx.db.ActiveBPTreeIdx.enabledKeyPosMap = true
@@ -803,13 +867,42 @@ func (tx *Tx) lock() {
 
 // unlock unlocks the database based on the transaction type.
 func (tx *Tx) unlock() {
-	if tx.writable {
+	if tx.snapshot != nil {
+		if tx.writable {
+			tx.db.conflicts.done(tx.snapshot.readTs)
+		}
+		tx.db.releaseSnapshot(tx.snapshot)
+	} else if tx.writable {
 		tx.db.mu.Unlock()
 	} else {
 		tx.db.mu.RUnlock()
 	}
 }
 
+// indexes returns the in-memory indexes the tx reads from, the snapshot of
+// the tx or the indexes of the db.
+func (tx *Tx) indexes() snapshot {
+	if tx.snapshot != nil {
+		return *tx.snapshot
+	}
+
+	return snapshot{
+		BTreeIdx:     tx.db.BTreeIdx,
+		SetIdx:       tx.db.SetIdx,
+		SortedSetIdx: tx.db.SortedSetIdx,
+		Index:        tx.db.Index,
+	}
+}
+
+// getList returns the list of the bucket the tx reads from, an empty list if it does not exist.
+func (tx *Tx) getList(bucket string) *List {
+	if l, ok := tx.indexes().Index.list[bucket]; ok {
+		return l
+	}
+
+	return NewList()
+}
+
 func (tx *Tx) handleErr(err error) {
 	if tx.db.opt.ErrorHandler != nil {
 		tx.db.opt.ErrorHandler.HandleError(err)
@@ -853,6 +946,13 @@ func (tx *Tx) put(bucket string, key, value []byte, ttl uint32, flag uint16, tim
 	if err != nil {
 		return err
 	}
+
+	if ds == DataStructureTree && flag == DataSetFlag {
+		if err := e.compress(tx.db.getCompressor(bucket)); err != nil {
+			return err
+		}
+	}
+
 	tx.pendingWrites = append(tx.pendingWrites, e)
 	tx.size += e.Size()
 
@@ -860,6 +960,11 @@ func (tx *Tx) put(bucket string, key, value []byte, ttl uint32, flag uint16, tim
 }
 
 func (tx *Tx) putDeleteLog(bucket string, key, value []byte, ttl uint32, flag uint16, timestamp uint64, ds uint16) {
+	// a read-only tx never writes, the expired data is removed by the writers.
+	if !tx.writable {
+		return
+	}
+
 	meta := NewMetaData().WithTimeStamp(timestamp).WithKeySize(uint32(len(key))).WithValueSize(uint32(len(value))).WithFlag(flag).
 		WithTTL(ttl).WithBucketSize(uint32(len(bucket))).WithStatus(UnCommitted).WithDs(ds).WithTxID(tx.id)
 
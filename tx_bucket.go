@@ -24,15 +24,18 @@ func (tx *Tx) IterateBuckets(ds uint16, pattern string, f func(key string) bool)
 	if tx.db.opt.EntryIdxMode == HintBPTSparseIdxMode {
 		return ErrNotSupportHintBPTSparseIdxMode
 	}
+
+	tx.trackRead(ds, "", nil)
+
 	if ds == DataStructureSet {
-		for bucket := range tx.db.SetIdx {
+		for bucket := range tx.indexes().SetIdx {
 			if end, err := MatchForRange(pattern, bucket, f); end || err != nil {
 				return err
 			}
 		}
 	}
 	if ds == DataStructureSortedSet {
-		for bucket := range tx.db.SortedSetIdx {
+		for bucket := range tx.indexes().SortedSetIdx {
 			if end, err := MatchForRange(pattern, bucket, f); end || err != nil {
 				return err
 			}
@@ -45,13 +48,13 @@ func (tx *Tx) IterateBuckets(ds uint16, pattern string, f func(key string) bool)
 			}
 			return nil
 		}
-		err := tx.db.Index.handleListBucket(f)
+		err := tx.indexes().Index.handleListBucket(f)
 		if err != nil {
 			return err
 		}
 	}
 	if ds == DataStructureTree {
-		for bucket := range tx.db.BTreeIdx {
+		for bucket := range tx.indexes().BTreeIdx {
 			if end, err := MatchForRange(pattern, bucket, f); end || err != nil {
 				return err
 			}
@@ -95,15 +98,17 @@ func (tx *Tx) DeleteBucket(ds uint16, bucket string) error {
 func (tx *Tx) ExistBucket(ds uint16, bucket string) (bool, error) {
 	var ok bool
 
+	tx.trackRead(ds, bucket, nil)
+
 	switch ds {
 	case DataStructureSet:
-		_, ok = tx.db.SetIdx[bucket]
+		_, ok = tx.indexes().SetIdx[bucket]
 	case DataStructureSortedSet:
-		_, ok = tx.db.SortedSetIdx[bucket]
+		_, ok = tx.indexes().SortedSetIdx[bucket]
 	case DataStructureTree:
-		_, ok = tx.db.BTreeIdx[bucket]
+		_, ok = tx.indexes().BTreeIdx[bucket]
 	case DataStructureList:
-		ok = tx.db.Index.existList(bucket)
+		ok = tx.indexes().Index.existList(bucket)
 	default:
 		return false, ErrDataStructureNotSupported
 	}
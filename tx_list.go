@@ -49,7 +49,7 @@ func (tx *Tx) RPeek(bucket string, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if l == nil {
 		return nil, ErrBucket
 	}
@@ -88,13 +88,11 @@ func (tx *Tx) RPush(bucket string, key []byte, values ...[]byte) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	if tx.CheckExpire(bucket, key) {
-		return ErrKeyNotFound
-	}
 	if strings.Contains(string(key), SeparatorForListKey) {
 		return ErrSeparatorForListKey
 	}
 
+	// pushing to an expired list starts a new list.
 	return tx.push(bucket, key, DataRPushFlag, values...)
 }
 
@@ -103,14 +101,12 @@ func (tx *Tx) LPush(bucket string, key []byte, values ...[]byte) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	if tx.CheckExpire(bucket, key) {
-		return ErrKeyNotFound
-	}
 
 	if strings.Contains(string(key), SeparatorForListKey) {
 		return ErrSeparatorForListKey
 	}
 
+	// pushing to an expired list starts a new list.
 	return tx.push(bucket, key, DataLPushFlag, values...)
 }
 
@@ -129,7 +125,7 @@ func (tx *Tx) LPeek(bucket string, key []byte) (item []byte, err error) {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return nil, err
 	}
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if l == nil {
 		return nil, ErrBucket
 	}
@@ -154,7 +150,7 @@ func (tx *Tx) LSize(bucket string, key []byte) (int, error) {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return 0, err
 	}
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if l == nil {
 		return 0, ErrBucket
 	}
@@ -173,7 +169,7 @@ func (tx *Tx) LRange(bucket string, key []byte, start, end int) ([][]byte, error
 	if err := tx.checkTxIsClosed(); err != nil {
 		return nil, err
 	}
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if l == nil {
 		return nil, ErrBucket
 	}
@@ -241,7 +237,7 @@ func (tx *Tx) LSet(bucket string, key []byte, index int, value []byte) error {
 	if err = tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if tx.CheckExpire(bucket, key) {
 		return ErrKeyNotFound
 	}
@@ -278,7 +274,7 @@ func (tx *Tx) LTrim(bucket string, key []byte, start, end int) error {
 		return err
 	}
 
-	l := tx.db.Index.getList(bucket)
+	l := tx.getList(bucket)
 	if tx.CheckExpire(bucket, key) {
 		return ErrKeyNotFound
 	}
@@ -330,7 +326,9 @@ func (tx *Tx) LKeys(bucket, pattern string, f func(key string) bool) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	l := tx.db.Index.getList(bucket)
+	tx.trackRead(DataStructureList, bucket, nil)
+
+	l := tx.getList(bucket)
 	if l == nil {
 		return ErrBucket
 	}
@@ -349,9 +347,6 @@ func (tx *Tx) ExpireList(bucket string, key []byte, ttl uint32) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	l := tx.db.Index.getList(bucket)
-	l.TTL[string(key)] = ttl
-	l.TimeStamp[string(key)] = uint64(time.Now().Unix())
 	ttls := strconv2.Int64ToStr(int64(ttl))
 	err := tx.push(bucket, key, DataExpireListFlag, []byte(ttls))
 	if err != nil {
@@ -361,9 +356,13 @@ func (tx *Tx) ExpireList(bucket string, key []byte, ttl uint32) error {
 }
 
 func (tx *Tx) CheckExpire(bucket string, key []byte) bool {
-	l := tx.db.Index.getList(bucket)
-	if l.IsExpire(string(key)) {
-		_ = tx.push(bucket, key, DataDeleteFlag)
+	tx.trackRead(DataStructureList, bucket, key)
+
+	l := tx.getList(bucket)
+	if l.isExpired(string(key)) {
+		if tx.writable {
+			_ = tx.push(bucket, key, DataDeleteFlag)
+		}
 		return true
 	}
 	return false
@@ -373,7 +372,9 @@ func (tx *Tx) GetListTTL(bucket string, key []byte) (uint32, error) {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return 0, err
 	}
-	l := tx.db.Index.getList(bucket)
+	tx.trackRead(DataStructureList, bucket, key)
+
+	l := tx.getList(bucket)
 	if l == nil {
 		return 0, ErrBucket
 	}
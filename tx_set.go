@@ -23,10 +23,11 @@ import (
 func (tx *Tx) sPut(bucket string, key []byte, dataFlag uint16, values ...[]byte) error {
 
 	if dataFlag == DataSetFlag {
+		tx.trackRead(DataStructureSet, bucket, key)
 
 		filter := make(map[uint32]struct{})
 
-		if set, ok := tx.db.SetIdx[bucket]; ok {
+		if set, ok := tx.indexes().SetIdx[bucket]; ok {
 
 			if _, ok := set.M[string(key)]; ok {
 				for hash := range set.M[string(key)] {
@@ -78,8 +79,10 @@ func (tx *Tx) SRem(bucket string, key []byte, items ...[]byte) error {
 		return err
 	}
 
-	if _, ok := tx.db.SetIdx[bucket]; ok {
-		ok, err := tx.db.SetIdx[bucket].SAreMembers(string(key), items...)
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if _, ok := tx.indexes().SetIdx[bucket]; ok {
+		ok, err := tx.indexes().SetIdx[bucket].SAreMembers(string(key), items...)
 		if err != nil {
 			return err
 		}
@@ -97,7 +100,9 @@ func (tx *Tx) SAreMembers(bucket string, key []byte, items ...[]byte) (bool, err
 		return false, err
 	}
 
-	if sets, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if sets, ok := tx.indexes().SetIdx[bucket]; ok {
 		return sets.SAreMembers(string(key), items...)
 	}
 
@@ -110,7 +115,9 @@ func (tx *Tx) SIsMember(bucket string, key, item []byte) (bool, error) {
 		return false, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		isMember, err := set.SIsMember(string(key), item)
 		if err != nil {
 			return false, err
@@ -127,7 +134,9 @@ func (tx *Tx) SMembers(bucket string, key []byte) ([][]byte, error) {
 		return nil, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		items, err := set.SMembers(string(key))
 		if err != nil {
 			return nil, err
@@ -154,7 +163,9 @@ func (tx *Tx) SHasKey(bucket string, key []byte) (bool, error) {
 		return false, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		return set.SHasKey(string(key)), nil
 	}
 
@@ -167,8 +178,10 @@ func (tx *Tx) SPop(bucket string, key []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	if _, ok := tx.db.SetIdx[bucket]; ok {
-		for _, items := range tx.db.SetIdx[bucket].M[string(key)] {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if _, ok := tx.indexes().SetIdx[bucket]; ok {
+		for _, items := range tx.indexes().SetIdx[bucket].M[string(key)] {
 			value, err := tx.db.getValueByRecord(items)
 			if err != nil {
 				return nil, err
@@ -190,7 +203,9 @@ func (tx *Tx) SCard(bucket string, key []byte) (int, error) {
 		return 0, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		return set.SCard(string(key)), nil
 	}
 
@@ -204,7 +219,10 @@ func (tx *Tx) SDiffByOneBucket(bucket string, key1, key2 []byte) ([][]byte, erro
 		return nil, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key1)
+	tx.trackRead(DataStructureSet, bucket, key2)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		items, err := set.SDiff(string(key1), string(key2))
 		if err != nil {
 			return nil, err
@@ -230,16 +248,19 @@ func (tx *Tx) SDiffByTwoBuckets(bucket1 string, key1 []byte, bucket2 string, key
 		return nil, err
 	}
 
+	tx.trackRead(DataStructureSet, bucket1, key1)
+	tx.trackRead(DataStructureSet, bucket2, key2)
+
 	var (
 		set1, set2 *Set
 		ok         bool
 	)
 
-	if set1, ok = tx.db.SetIdx[bucket1]; !ok {
+	if set1, ok = tx.indexes().SetIdx[bucket1]; !ok {
 		return nil, ErrBucketAndKey(bucket1, key1)
 	}
 
-	if set2, ok = tx.db.SetIdx[bucket2]; !ok {
+	if set2, ok = tx.indexes().SetIdx[bucket2]; !ok {
 		return nil, ErrBucketAndKey(bucket2, key2)
 	}
 
@@ -264,8 +285,14 @@ func (tx *Tx) SMoveByOneBucket(bucket string, key1, key2, item []byte) (bool, er
 		return false, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
-		return set.SMove(string(key1), string(key2), item)
+	tx.trackRead(DataStructureSet, bucket, key1)
+	tx.trackRead(DataStructureSet, bucket, key2)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
+		if !set.SHasKey(string(key1)) || !set.SHasKey(string(key2)) {
+			return false, ErrSetNotExist
+		}
+		return tx.sMove(bucket, key1, set, bucket, key2, item)
 	}
 
 	return false, ErrBucket
@@ -277,16 +304,19 @@ func (tx *Tx) SMoveByTwoBuckets(bucket1 string, key1 []byte, bucket2 string, key
 		return false, err
 	}
 
+	tx.trackRead(DataStructureSet, bucket1, key1)
+	tx.trackRead(DataStructureSet, bucket2, key2)
+
 	var (
 		set1, set2 *Set
 		ok         bool
 	)
 
-	if set1, ok = tx.db.SetIdx[bucket1]; !ok {
+	if set1, ok = tx.indexes().SetIdx[bucket1]; !ok {
 		return false, ErrBucketAndKey(bucket1, key1)
 	}
 
-	if set2, ok = tx.db.SetIdx[bucket2]; !ok {
+	if set2, ok = tx.indexes().SetIdx[bucket2]; !ok {
 		return false, ErrBucketAndKey(bucket2, key1)
 	}
 
@@ -298,20 +328,25 @@ func (tx *Tx) SMoveByTwoBuckets(bucket1 string, key1 []byte, bucket2 string, key
 		return false, ErrNotFoundKeyInBucket(bucket2, key2)
 	}
 
-	hash, err := getFnv32(item)
+	return tx.sMove(bucket1, key1, set1, bucket2, key2, item)
+}
+
+// sMove moves the member from the set at source to the set at destination, the sets
+// are changed when the tx is committed.
+func (tx *Tx) sMove(bucket1 string, key1 []byte, set1 *Set, bucket2 string, key2, item []byte) (bool, error) {
+	isMember, err := set1.SIsMember(string(key1), item)
 	if err != nil {
 		return false, err
 	}
+	if !isMember {
+		return false, ErrSetMemberNotExist
+	}
 
-	if r, ok := set2.M[string(key2)][hash]; !ok {
-		err := set2.SAdd(string(key2), [][]byte{item}, []*Record{r})
-		if err != nil {
-			return false, err
-		}
+	if err := tx.sPut(bucket2, key2, DataSetFlag, item); err != nil {
+		return false, err
 	}
 
-	err = set1.SRem(string(key1), item)
-	if err != nil {
+	if err := tx.sPut(bucket1, key1, DataDeleteFlag, item); err != nil {
 		return false, err
 	}
 
@@ -324,7 +359,10 @@ func (tx *Tx) SUnionByOneBucket(bucket string, key1, key2 []byte) ([][]byte, err
 		return nil, err
 	}
 
-	if set, ok := tx.db.SetIdx[bucket]; ok {
+	tx.trackRead(DataStructureSet, bucket, key1)
+	tx.trackRead(DataStructureSet, bucket, key2)
+
+	if set, ok := tx.indexes().SetIdx[bucket]; ok {
 		items, err := set.SUnion(string(key1), string(key2))
 		if err != nil {
 			return nil, err
@@ -351,16 +389,19 @@ func (tx *Tx) SUnionByTwoBuckets(bucket1 string, key1 []byte, bucket2 string, ke
 		return nil, err
 	}
 
+	tx.trackRead(DataStructureSet, bucket1, key1)
+	tx.trackRead(DataStructureSet, bucket2, key2)
+
 	var (
 		set1, set2 *Set
 		ok         bool
 	)
 
-	if set1, ok = tx.db.SetIdx[bucket1]; !ok {
+	if set1, ok = tx.indexes().SetIdx[bucket1]; !ok {
 		return nil, ErrBucketAndKey(bucket1, key1)
 	}
 
-	if set2, ok = tx.db.SetIdx[bucket2]; !ok {
+	if set2, ok = tx.indexes().SetIdx[bucket2]; !ok {
 		return nil, ErrBucketAndKey(bucket2, key1)
 	}
 
@@ -400,10 +441,12 @@ func (tx *Tx) SKeys(bucket, pattern string, f func(key string) bool) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	if _, ok := tx.db.SetIdx[bucket]; !ok {
+
+	tx.trackRead(DataStructureSet, bucket, nil)
+	if _, ok := tx.indexes().SetIdx[bucket]; !ok {
 		return ErrBucket
 	}
-	for key := range tx.db.SetIdx[bucket].M {
+	for key := range tx.indexes().SetIdx[bucket].M {
 		if end, err := MatchForRange(pattern, key, f); end || err != nil {
 			return err
 		}
@@ -150,7 +150,9 @@ func (tx *Tx) Get(bucket string, key []byte) (e *Entry, err error) {
 		return tx.getByHintBPTSparseIdx(bucket, key)
 	}
 
-	if idx, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, key)
+
+	if idx, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		r, found := idx.Find(key)
 		if !found {
 			return nil, ErrKeyNotFound
@@ -193,7 +195,9 @@ func (tx *Tx) GetAll(bucket string) (entries Entries, err error) {
 		return tx.getAllByHintBPTSparseIdx(bucket)
 	}
 
-	if index, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, nil)
+
+	if index, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		records := index.All()
 		if len(records) == 0 {
 			return nil, ErrBucketEmpty
@@ -260,7 +264,9 @@ func (tx *Tx) RangeScan(bucket string, start, end []byte) (es Entries, err error
 		return es.ToCEntries(tx.db.opt.LessFunc).processEntriesScanOnDisk(), nil
 	}
 
-	if index, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, nil)
+
+	if index, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		records := index.Range(start, end)
 		if err != nil {
 			return nil, ErrRangeScan
@@ -774,7 +780,9 @@ func (tx *Tx) PrefixScan(bucket string, prefix []byte, offsetNum int, limitNum i
 		return entries, err
 	}
 
-	if idx, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, nil)
+
+	if idx, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		records := idx.PrefixScan(prefix, offsetNum, limitNum)
 		es, err = tx.getHintIdxDataItemsWrapper(records, limitNum, es, PrefixScan)
 		if err != nil {
@@ -802,7 +810,9 @@ func (tx *Tx) PrefixSearchScan(bucket string, prefix []byte, reg string, offsetN
 		return entries, err
 	}
 
-	if idx, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, nil)
+
+	if idx, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		records := idx.PrefixSearchScan(prefix, reg, offsetNum, limitNum)
 		es, err = tx.getHintIdxDataItemsWrapper(records, limitNum, es, PrefixSearchScan)
 		if err != nil {
@@ -832,7 +842,9 @@ func (tx *Tx) Delete(bucket string, key []byte) error {
 		return nil
 	}
 
-	if idx, ok := tx.db.BTreeIdx[bucket]; ok {
+	tx.trackRead(DataStructureTree, bucket, key)
+
+	if idx, ok := tx.indexes().BTreeIdx[bucket]; ok {
 		if _, found := idx.Find(key); !found {
 			return ErrNotFoundKey
 		}
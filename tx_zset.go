@@ -51,11 +51,13 @@ func (tx *Tx) ZAdd(bucket string, key []byte, score float64, val []byte) error {
 
 // ZMembers Returns all the members and scores of members of the set specified by key in a bucket.
 func (tx *Tx) ZMembers(bucket string, key []byte) (map[*SortedSetMember]struct{}, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	members, err := tx.db.SortedSetIdx[bucket].ZMembers(string(key))
+	members, err := tx.indexes().SortedSetIdx[bucket].ZMembers(string(key))
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +79,13 @@ func (tx *Tx) ZMembers(bucket string, key []byte) (map[*SortedSetMember]struct{}
 
 // ZCard Returns the sorted set cardinality (number of elements) of the sorted set specified by key in a bucket.
 func (tx *Tx) ZCard(bucket string, key []byte) (int, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return 0, err
 	}
 
-	card, err := tx.db.SortedSetIdx[bucket].ZCard(string(key))
+	card, err := tx.indexes().SortedSetIdx[bucket].ZCard(string(key))
 	if err != nil {
 		return 0, err
 	}
@@ -94,11 +98,13 @@ func (tx *Tx) ZCard(bucket string, key []byte) (int, error) {
 // ExcludeStart bool // exclude start value, so it search in interval (start, end] or (start, end)
 // ExcludeEnd   bool // exclude end value, so it search in interval [start, end) or (start, end)
 func (tx *Tx) ZCount(bucket string, key []byte, start, end float64, opts *GetByScoreRangeOptions) (int, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return 0, err
 	}
 
-	count, err := tx.db.SortedSetIdx[bucket].ZCount(string(key), SCORE(start), SCORE(end), opts)
+	count, err := tx.indexes().SortedSetIdx[bucket].ZCount(string(key), SCORE(start), SCORE(end), opts)
 	if err != nil {
 		return 0, err
 	}
@@ -108,11 +114,13 @@ func (tx *Tx) ZCount(bucket string, key []byte, start, end float64, opts *GetByS
 
 // ZPopMax Removes and returns the member with the highest score in the sorted set specified by key in a bucket.
 func (tx *Tx) ZPopMax(bucket string, key []byte) (*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	record, score, err := tx.db.SortedSetIdx[bucket].ZPeekMax(string(key))
+	record, score, err := tx.indexes().SortedSetIdx[bucket].ZPeekMax(string(key))
 	if err != nil {
 		return nil, err
 	}
@@ -127,11 +135,13 @@ func (tx *Tx) ZPopMax(bucket string, key []byte) (*SortedSetMember, error) {
 
 // ZPopMin Removes and returns the member with the lowest score in the sorted set specified by key in a bucket.
 func (tx *Tx) ZPopMin(bucket string, key []byte) (*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	record, score, err := tx.db.SortedSetIdx[bucket].ZPeekMin(string(key))
+	record, score, err := tx.indexes().SortedSetIdx[bucket].ZPeekMin(string(key))
 	if err != nil {
 		return nil, err
 	}
@@ -146,11 +156,13 @@ func (tx *Tx) ZPopMin(bucket string, key []byte) (*SortedSetMember, error) {
 
 // ZPeekMax Returns the member with the highest score in the sorted set specified by key in a bucket.
 func (tx *Tx) ZPeekMax(bucket string, key []byte) (*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	record, score, err := tx.db.SortedSetIdx[bucket].ZPeekMax(string(key))
+	record, score, err := tx.indexes().SortedSetIdx[bucket].ZPeekMax(string(key))
 	if err != nil {
 		return nil, err
 	}
@@ -165,11 +177,13 @@ func (tx *Tx) ZPeekMax(bucket string, key []byte) (*SortedSetMember, error) {
 
 // ZPeekMin Returns the member with the lowest score in the sorted set specified by key in a bucket.
 func (tx *Tx) ZPeekMin(bucket string, key []byte) (*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	record, score, err := tx.db.SortedSetIdx[bucket].ZPeekMin(string(key))
+	record, score, err := tx.indexes().SortedSetIdx[bucket].ZPeekMin(string(key))
 	if err != nil {
 		return nil, err
 	}
@@ -185,11 +199,13 @@ func (tx *Tx) ZPeekMin(bucket string, key []byte) (*SortedSetMember, error) {
 // ZRangeByScore Returns all the elements in the sorted set specified by key in a bucket with a score between min and max.
 // And the parameter `Opts` is the same as ZCount's.
 func (tx *Tx) ZRangeByScore(bucket string, key []byte, start, end float64, opts *GetByScoreRangeOptions) ([]*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	records, scores, err := tx.db.SortedSetIdx[bucket].ZRangeByScore(string(key), SCORE(start), SCORE(end), opts)
+	records, scores, err := tx.indexes().SortedSetIdx[bucket].ZRangeByScore(string(key), SCORE(start), SCORE(end), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -209,11 +225,13 @@ func (tx *Tx) ZRangeByScore(bucket string, key []byte, start, end float64, opts
 // ZRangeByRank Returns all the elements in the sorted set specified by key in a bucket
 // with a rank between start and end (including elements with rank equal to start or end).
 func (tx *Tx) ZRangeByRank(bucket string, key []byte, start, end int) ([]*SortedSetMember, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return nil, err
 	}
 
-	records, scores, err := tx.db.SortedSetIdx[bucket].ZRangeByRank(string(key), start, end)
+	records, scores, err := tx.indexes().SortedSetIdx[bucket].ZRangeByRank(string(key), start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -232,11 +250,13 @@ func (tx *Tx) ZRangeByRank(bucket string, key []byte, start, end int) ([]*Sorted
 
 // ZRem removes the specified members from the sorted set stored in one bucket at given bucket and key.
 func (tx *Tx) ZRem(bucket string, key []byte, value []byte) error {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return err
 	}
 
-	exist, err := tx.db.SortedSetIdx[bucket].ZExist(string(key), value)
+	exist, err := tx.indexes().SortedSetIdx[bucket].ZExist(string(key), value)
 	if err != nil {
 		return err
 	}
@@ -251,6 +271,8 @@ func (tx *Tx) ZRem(bucket string, key []byte, value []byte) error {
 // ZRemRangeByRank removes all elements in the sorted set stored in one bucket at given bucket with rank between start and end.
 // the rank is 1-based integer. Rank 1 means the first node; Rank -1 means the last node.
 func (tx *Tx) ZRemRangeByRank(bucket string, key []byte, start, end int) error {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return err
 	}
@@ -262,29 +284,35 @@ func (tx *Tx) ZRemRangeByRank(bucket string, key []byte, start, end int) error {
 
 // ZRank Returns the rank of member in the sorted set specified by key in a bucket, with the scores ordered from low to high.
 func (tx *Tx) ZRank(bucket string, key, value []byte) (int, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return 0, err
 	}
 
-	return tx.db.SortedSetIdx[bucket].ZRank(string(key), value)
+	return tx.indexes().SortedSetIdx[bucket].ZRank(string(key), value)
 }
 
 // ZRevRank Returns the rank of member in the sorted set specified by key in a bucket, with the scores ordered from high to low.
 func (tx *Tx) ZRevRank(bucket string, key, value []byte) (int, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return 0, err
 	}
 
-	return tx.db.SortedSetIdx[bucket].ZRevRank(string(key), value)
+	return tx.indexes().SortedSetIdx[bucket].ZRevRank(string(key), value)
 }
 
 // ZScore Returns the score of members in a sorted set specified by key in a bucket.
 func (tx *Tx) ZScore(bucket string, key, value []byte) (float64, error) {
+	tx.trackRead(DataStructureSortedSet, bucket, key)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return 0, err
 	}
 
-	if score, err := tx.db.SortedSetIdx[bucket].ZScore(string(key), value); err != nil {
+	if score, err := tx.indexes().SortedSetIdx[bucket].ZScore(string(key), value); err != nil {
 		return 0, err
 	} else {
 		return score, nil
@@ -293,10 +321,12 @@ func (tx *Tx) ZScore(bucket string, key, value []byte) (float64, error) {
 
 // ZKeys find all keys matching a given pattern in a bucket
 func (tx *Tx) ZKeys(bucket, pattern string, f func(key string) bool) error {
+	tx.trackRead(DataStructureSortedSet, bucket, nil)
+
 	if err := tx.ZCheck(bucket); err != nil {
 		return err
 	}
-	for key := range tx.db.SortedSetIdx[bucket].M {
+	for key := range tx.indexes().SortedSetIdx[bucket].M {
 		if end, err := MatchForRange(pattern, key, f); end || err != nil {
 			return err
 		}
@@ -308,7 +338,7 @@ func (tx *Tx) ZCheck(bucket string) error {
 	if err := tx.checkTxIsClosed(); err != nil {
 		return err
 	}
-	if _, ok := tx.db.SortedSetIdx[bucket]; !ok {
+	if _, ok := tx.indexes().SortedSetIdx[bucket]; !ok {
 		return ErrBucket
 	}
 	return nil
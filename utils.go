@@ -81,6 +81,12 @@ func getDataPath(fID int64, dir string) string {
 	return dir + separator + strconv2.Int64ToStr(fID) + DataSuffix
 }
 
+// getHintPath returns the hint file path for the given file ID.
+func getHintPath(fID int64, dir string) string {
+	separator := string(filepath.Separator)
+	return dir + separator + strconv2.Int64ToStr(fID) + HintFileSuffix
+}
+
 // getMetaPath returns the path for the meta file in the specified directory.
 func getMetaPath(dir string) string {
 	separator := string(filepath.Separator)